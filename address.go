@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// AddressType identifies one of the supported address derivation schemes
+type AddressType int
+
+const (
+	AddressP2PKH AddressType = iota
+	AddressP2WPKHInP2SH
+	AddressP2WPKH
+	AddressTaproot
+)
+
+// AddressTypes lists all supported address types in output order
+var AddressTypes = []AddressType{AddressP2PKH, AddressP2WPKHInP2SH, AddressP2WPKH, AddressTaproot}
+
+// Purpose returns the BIP-43 purpose of the address type's derivation path
+func (t AddressType) Purpose() uint32 {
+	switch t {
+	case AddressP2PKH:
+		return 44
+	case AddressP2WPKHInP2SH:
+		return 49
+	case AddressP2WPKH:
+		return 84
+	case AddressTaproot:
+		return 86
+	default:
+		return 0
+	}
+}
+
+// Name returns the short identifier of the address type used in flags and files
+func (t AddressType) Name() string {
+	switch t {
+	case AddressP2PKH:
+		return "p2pkh"
+	case AddressP2WPKHInP2SH:
+		return "p2sh-p2wpkh"
+	case AddressP2WPKH:
+		return "p2wpkh"
+	case AddressTaproot:
+		return "p2tr"
+	default:
+		return ""
+	}
+}
+
+// ParseAddressType returns the address type with the given short name
+func ParseAddressType(name string) (AddressType, error) {
+	for _, t := range AddressTypes {
+		if t.Name() == name {
+			return t, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown address type %q", name)
+}
+
+// AddressTypeForPurpose returns the address type derived under the BIP-43 purpose
+func AddressTypeForPurpose(bip uint32) (AddressType, error) {
+	for _, t := range AddressTypes {
+		if t.Purpose() == bip {
+			return t, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported purpose %d", bip)
+}
+
+// ParseAddressTypes parses a comma separated list of address type names
+func ParseAddressTypes(list string) ([]AddressType, error) {
+	var types []AddressType
+	for _, name := range strings.Split(list, ",") {
+		t, err := ParseAddressType(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		types = append(types, t)
+	}
+
+	return types, nil
+}
+
+func (t AddressType) String() string {
+	switch t {
+	case AddressP2PKH:
+		return "BIP-44 P2PKH"
+	case AddressP2WPKHInP2SH:
+		return "BIP-49 P2WPKH-in-P2SH"
+	case AddressP2WPKH:
+		return "BIP-84 P2WPKH"
+	case AddressTaproot:
+		return "BIP-86 P2TR"
+	default:
+		return fmt.Sprintf("AddressType(%d)", int(t))
+	}
+}
+
+// Bech32 reports whether the address type encodes a witness program directly
+// as a bech32 or bech32m address
+func (t AddressType) Bech32() bool {
+	return t == AddressP2WPKH || t == AddressTaproot
+}
+
+// DerivationPath returns the path of the address at index of the change
+// chain for the purpose, e.g. m/84'/0'/0'/0/5
+func (w *Wallet) DerivationPath(bip uint32, change uint32, index uint32) string {
+	return FormatPath(PathArray(bip, w.Params.HDCoinType, change, index))
+}
+
+// PathArray returns the path of DerivationPath as the array of child numbers
+// device SDKs such as Ledger's and Trezor's take, the purpose, coin type and
+// account levels with the hardened offset added
+func PathArray(bip uint32, coinType uint32, change uint32, index uint32) []uint32 {
+	return []uint32{
+		hdkeychain.HardenedKeyStart + bip,
+		hdkeychain.HardenedKeyStart + coinType,
+		hdkeychain.HardenedKeyStart + 0,
+		change,
+		index,
+	}
+}
+
+// IsHardened reports whether the child number is a hardened level
+func IsHardened(level uint32) bool {
+	return level >= hdkeychain.HardenedKeyStart
+}
+
+// FormatLevel writes the child number as a path level: hardened levels as
+// their index with a ' suffix, e.g. 2147483732 as 84'
+func FormatLevel(level uint32) string {
+	if IsHardened(level) {
+		return strconv.FormatUint(uint64(level-hdkeychain.HardenedKeyStart), 10) + "'"
+	}
+
+	return strconv.FormatUint(uint64(level), 10)
+}
+
+// ParseLevel parses a path level of FormatLevel into its child number, also
+// accepting the h suffix of descriptors for hardened levels
+func ParseLevel(level string) (uint32, error) {
+	digits := strings.TrimRight(level, "'h")
+	hardened := len(digits) < len(level)
+	if len(level)-len(digits) > 1 {
+		return 0, fmt.Errorf("invalid path level %q", level)
+	}
+
+	index, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil || index >= hdkeychain.HardenedKeyStart {
+		return 0, fmt.Errorf("invalid path level %q: the index must be below %d", level, uint32(hdkeychain.HardenedKeyStart))
+	}
+
+	if hardened {
+		index += hdkeychain.HardenedKeyStart
+	}
+
+	return uint32(index), nil
+}
+
+// FormatPath writes the child numbers as a path string, e.g. m/84'/0'/0'/0/5
+func FormatPath(levels []uint32) string {
+	path := "m"
+	for _, level := range levels {
+		path += "/" + FormatLevel(level)
+	}
+
+	return path
+}
+
+// formatPathArray writes the array as [2147483732, 2147483648, ...]
+func formatPathArray(path []uint32) string {
+	levels := make([]string, len(path))
+	for i, level := range path {
+		levels[i] = strconv.FormatUint(uint64(level), 10)
+	}
+
+	return "[" + strings.Join(levels, ", ") + "]"
+}
+
+// ChainName returns the label of the receive (change 0) or change (change 1)
+// chain, other chains are labeled with their number
+func ChainName(change uint32) string {
+	switch change {
+	case 0:
+		return "Receive"
+	case 1:
+		return "Change"
+	default:
+		return fmt.Sprintf("Chain %d", change)
+	}
+}
+
+// ValidateChange checks the -change flag. BIP-44 only defines the receive
+// (0) and change (1) chains, but the level is an ordinary unhardened index
+// some privacy schemes rotate through. Other chains are allowed with a
+// warning, as standard wallets never scan them.
+func ValidateChange(change int) error {
+	if change < 0 || int64(change) >= hdkeychain.HardenedKeyStart {
+		return fmt.Errorf("invalid -change %d: must be an unhardened index, 0 (receive) or 1 (change) in standard wallets", change)
+	}
+
+	if change > 1 {
+		slog.Warn("chains other than 0 (receive) and 1 (change) are non-standard, other wallets will not find their funds", "change", change)
+	}
+
+	return nil
+}
+
+// AddressSet holds the address of each type at a single derivation index
+type AddressSet struct {
+	Change            uint32
+	Index             uint32
+	P2pkhAddress      btcutil.Address
+	P2wpkhP2shAddress btcutil.Address
+	P2wpkhAddress     btcutil.Address
+	TaprootAddress    btcutil.Address
+
+	// TapTweak is the key-path tweak of the Taproot address, set when requested
+	TapTweak []byte
+
+	// ChildXprvs are the extended private keys of the addresses keyed by
+	// type, set only for -export-child-xprv
+	ChildXprvs map[AddressType]string
+}
+
+// Address returns the address of the given type in the set
+func (s AddressSet) Address(t AddressType) btcutil.Address {
+	switch t {
+	case AddressP2PKH:
+		return s.P2pkhAddress
+	case AddressP2WPKHInP2SH:
+		return s.P2wpkhP2shAddress
+	case AddressP2WPKH:
+		return s.P2wpkhAddress
+	case AddressTaproot:
+		return s.TaprootAddress
+	default:
+		return nil
+	}
+}
+
+// ScriptPubKeyHex returns the hex encoded output script paying to address
+func ScriptPubKeyHex(address btcutil.Address) (string, error) {
+	script, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return "", fmt.Errorf("error creating output script: %w", err)
+	}
+
+	return hex.EncodeToString(script), nil
+}
+
+// witnessAddress is implemented by the bech32 address types
+type witnessAddress interface {
+	WitnessVersion() byte
+	WitnessProgram() []byte
+}
+
+// WitnessProgram returns the witness version and the hex encoded witness
+// program of a SegWit address
+func WitnessProgram(address btcutil.Address) (byte, string, error) {
+	witness, ok := address.(witnessAddress)
+	if !ok {
+		return 0, "", fmt.Errorf("address %s has no witness program", address.EncodeAddress())
+	}
+
+	return witness.WitnessVersion(), hex.EncodeToString(witness.WitnessProgram()), nil
+}
+
+// DeriveAddress derives the address of the given type at index of the change chain
+func (w *Wallet) DeriveAddress(t AddressType, change uint32, index uint32) (btcutil.Address, error) {
+	switch t {
+	case AddressP2PKH:
+		return w.DeriveP2PKHAddress(change, index)
+	case AddressP2WPKHInP2SH:
+		return w.DeriveP2WPKHInP2SHAddress(change, index)
+	case AddressP2WPKH:
+		return w.DeriveP2WPKHAddress(change, index)
+	case AddressTaproot:
+		return w.DeriveTaprootAddress(change, index, nil)
+	default:
+		return nil, fmt.Errorf("unknown address type %d", int(t))
+	}
+}
+
+// DeriveIndices derives the addresses of the given type at each index. The
+// chain node is shared, so only the requested leaves are derived.
+func (w *Wallet) DeriveIndices(t AddressType, change uint32, indices []uint32) ([]btcutil.Address, error) {
+	addresses := make([]btcutil.Address, 0, len(indices))
+	for _, index := range indices {
+		address, err := w.DeriveAddress(t, change, index)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving %s address at index %d: %w", t, index, err)
+		}
+
+		addresses = append(addresses, address)
+	}
+
+	return addresses, nil
+}
+
+// ParseIndices parses a comma separated list of non-hardened indices,
+// dropping duplicates while keeping the order of first occurrence
+func ParseIndices(list string) ([]uint32, error) {
+	var indices []uint32
+	seen := make(map[uint32]bool)
+
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if len(field) == 0 {
+			continue
+		}
+
+		index, err := strconv.ParseUint(field, 10, 32)
+		if err != nil || index >= hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("invalid index %q", field)
+		}
+
+		if seen[uint32(index)] {
+			continue
+		}
+		seen[uint32(index)] = true
+
+		indices = append(indices, uint32(index))
+	}
+
+	if len(indices) == 0 {
+		return nil, fmt.Errorf("no indices given")
+	}
+
+	return indices, nil
+}
+
+// defaultMaxIndex is the default -max-index, well beyond the addresses of
+// wallets in everyday use
+const defaultMaxIndex = 10000
+
+// CheckIndexThreshold guards explicitly requested indices: one above the
+// threshold is more likely a typo than a deliberate lookup, so it is refused
+// unless forced and logged when forced
+func CheckIndexThreshold(indices []uint32, threshold uint32, force bool) error {
+	for _, index := range indices {
+		if index <= threshold {
+			continue
+		}
+
+		if !force {
+			return fmt.Errorf("requested index %d is above the threshold of %d, check it for a typo or use -force to derive it", index, threshold)
+		}
+
+		slog.Warn("deriving an unusually deep index", "index", index, "threshold", threshold)
+	}
+
+	return nil
+}
+
+// DeriveAll derives the address of every type at index of the change chain
+func (w *Wallet) DeriveAll(change uint32, index uint32) (AddressSet, error) {
+	set := AddressSet{Change: change, Index: index}
+
+	for _, t := range AddressTypes {
+		address, err := w.DeriveAddress(t, change, index)
+		if err != nil {
+			return AddressSet{}, fmt.Errorf("error deriving %s address: %w", t, err)
+		}
+
+		switch t {
+		case AddressP2PKH:
+			set.P2pkhAddress = address
+		case AddressP2WPKHInP2SH:
+			set.P2wpkhP2shAddress = address
+		case AddressP2WPKH:
+			set.P2wpkhAddress = address
+		case AddressTaproot:
+			set.TaprootAddress = address
+		}
+	}
+
+	return set, nil
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// TestWitnessPrograms checks the witness version and program length of the
+// bech32 address types: version 0 with a 20 byte key hash for P2WPKH and
+// version 1 with a 32 byte output key for P2TR
+func TestWitnessPrograms(t *testing.T) {
+	wallet := testWallet(t)
+
+	for _, v := range []struct {
+		typ     AddressType
+		version byte
+		length  int
+	}{
+		{AddressP2WPKH, 0, 20},
+		{AddressTaproot, 1, 32},
+	} {
+		address, err := wallet.DeriveAddress(v.typ, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		version, program, err := WitnessProgram(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if version != v.version || len(program) != 2*v.length {
+			t.Fatalf("%s: witness version %d with %d byte program, expected version %d with %d bytes", v.typ, version, len(program)/2, v.version, v.length)
+		}
+	}
+}
+
+// TestNonstandardChain checks chain 2 derives addresses of its own, the same
+// from the mnemonic and from the account zpub
+func TestNonstandardChain(t *testing.T) {
+	wallet := testWallet(t)
+
+	seen := make(map[string]uint32)
+	for change := uint32(0); change <= 2; change++ {
+		address, err := wallet.DeriveAddress(AddressP2WPKH, change, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if other, ok := seen[address.EncodeAddress()]; ok {
+			t.Fatalf("chains %d and %d derive the same address %s", other, change, address.EncodeAddress())
+		}
+		seen[address.EncodeAddress()] = change
+	}
+
+	account, typ, _, err := ParseExtendedPublicKey(bip84Zpub, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watchOnly, err := NewWatchOnlyWallet(account, typ, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watched, err := watchOnly.DeriveAddress(AddressP2WPKH, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seen[watched.EncodeAddress()] != 2 {
+		t.Fatalf("zpub chain 2 address %s does not match the mnemonic", watched.EncodeAddress())
+	}
+
+	// A hardened chain only fits the int of the flag on 64 bit platforms
+	var hardened int64 = hdkeychain.HardenedKeyStart
+	if strconv.IntSize == 64 {
+		if err := ValidateChange(int(hardened)); err == nil {
+			t.Fatalf("hardened chain %d was accepted", hardened)
+		}
+	}
+
+	if err := ValidateChange(-1); err == nil {
+		t.Fatalf("negative chain was accepted")
+	}
+}
+
+// TestFindPath checks FindPath locates a change address of each type and
+// reports addresses outside the search bound as not found
+func TestFindPath(t *testing.T) {
+	wallet := testWallet(t)
+
+	for _, typ := range AddressTypes {
+		address, err := wallet.DeriveAddress(typ, 1, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		path, err := wallet.FindPath(address, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := wallet.DerivationPath(typ.Purpose(), 1, 7); path != expected {
+			t.Fatalf("%s address found at %s, expected %s", typ, path, expected)
+		}
+
+		if _, err := wallet.FindPath(address, 6); err == nil {
+			t.Fatalf("%s address found beyond the max index", typ)
+		}
+	}
+}
+
+// TestPathArray checks the hardened offset is applied to exactly the purpose,
+// coin type and account levels and that deriving the array from the master
+// key reaches the address key
+func TestPathArray(t *testing.T) {
+	wallet := testWallet(t)
+
+	path := PathArray(84, wallet.Params.HDCoinType, 1, 5)
+	if len(path) != 5 {
+		t.Fatalf("path array %v has %d levels, expected 5", path, len(path))
+	}
+
+	for i, level := range path {
+		if hardened := level >= 0x80000000; hardened != (i < 3) {
+			t.Fatalf("path array %v level %d hardened %t", path, i, hardened)
+		}
+	}
+
+	key := wallet.MasterKey
+	for _, level := range path {
+		var err error
+		key, err = key.Derive(level)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := wallet.ExtendMasterKey(84, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key.String() != expected.String() {
+		t.Fatalf("path array %v derives a different key than %s", path, wallet.DerivationPath(84, 1, 5))
+	}
+
+	if formatted := formatPathArray(PathArray(44, 0, 0, 0)); formatted != "[2147483692, 2147483648, 2147483648, 0, 0]" {
+		t.Fatalf("path array formatted as %s", formatted)
+	}
+}
+
+// TestIndexThreshold checks indices up to the threshold pass, a deeper one is
+// refused naming both numbers and is only allowed when forced
+func TestIndexThreshold(t *testing.T) {
+	discardLogs(t)
+
+	if err := CheckIndexThreshold([]uint32{0, 7, 100}, 100, false); err != nil {
+		t.Fatalf("indices within the threshold refused: %v", err)
+	}
+
+	err := CheckIndexThreshold([]uint32{7, 500000}, 100, false)
+	if err == nil || !strings.Contains(err.Error(), "500000") || !strings.Contains(err.Error(), "100") {
+		t.Fatalf("deep index returned %v, expected an error naming the index and threshold", err)
+	}
+
+	if err := CheckIndexThreshold([]uint32{500000}, 100, true); err != nil {
+		t.Fatalf("forced deep index refused: %v", err)
+	}
+}
+
+// TestPathLevels converts the boundary child numbers to path levels and back
+// and rejects malformed or out of range levels
+func TestPathLevels(t *testing.T) {
+	for _, v := range []struct {
+		level    uint32
+		text     string
+		hardened bool
+	}{
+		{0, "0", false},
+		{0x7fffffff, "2147483647", false},
+		{0x80000000, "0'", true},
+		{0x80000000 + 84, "84'", true},
+		{0xffffffff, "2147483647'", true},
+	} {
+		if IsHardened(v.level) != v.hardened {
+			t.Fatalf("level %#x hardened %t, expected %t", v.level, IsHardened(v.level), v.hardened)
+		}
+
+		if text := FormatLevel(v.level); text != v.text {
+			t.Fatalf("level %#x formatted as %s, expected %s", v.level, text, v.text)
+		}
+
+		level, err := ParseLevel(v.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if level != v.level {
+			t.Fatalf("level %s parsed as %#x, expected %#x", v.text, level, v.level)
+		}
+	}
+
+	if level, err := ParseLevel("84h"); err != nil || level != 0x80000000+84 {
+		t.Fatalf("level 84h parsed as %#x, %v", level, err)
+	}
+
+	for _, text := range []string{"", "'", "2147483648", "2147483648'", "84''", "-1", "0x10"} {
+		if level, err := ParseLevel(text); err == nil {
+			t.Fatalf("invalid level %q parsed as %#x", text, level)
+		}
+	}
+
+	if path := FormatPath(PathArray(84, 0, 1, 5)); path != "m/84'/0'/0'/1/5" {
+		t.Fatalf("path array formatted as %s, expected m/84'/0'/0'/1/5", path)
+	}
+}
+
+// TestScriptPubKeyRoundTrip checks the scriptPubKey of every address type
+// decodes back to the same address with txscript.ExtractPkScriptAddrs
+func TestScriptPubKeyRoundTrip(t *testing.T) {
+	for _, params := range []*chaincfg.Params{&chaincfg.MainNetParams, &chaincfg.TestNet3Params} {
+		wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		set, err := wallet.DeriveAll(0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, typ := range AddressTypes {
+			address := set.Address(typ)
+
+			script, err := ScriptPubKeyHex(address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := hex.DecodeString(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, extracted, required, err := txscript.ExtractPkScriptAddrs(decoded, params)
+			if err != nil {
+				t.Fatalf("%s %s scriptPubKey %s: %v", params.Name, typ.Name(), script, err)
+			}
+
+			if len(extracted) != 1 || required != 1 || extracted[0].EncodeAddress() != address.EncodeAddress() {
+				t.Errorf("%s %s scriptPubKey %s decodes to %v, expected %s", params.Name, typ.Name(), script, extracted, address)
+			}
+		}
+	}
+}
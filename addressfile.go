@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Statuses of an AddressCheck
+const (
+	AddressValid        = "valid"
+	AddressInvalid      = "invalid"
+	AddressWrongNetwork = "wrong network"
+)
+
+// AddressCheck is the result of validating one line of an address file
+type AddressCheck struct {
+	Line    int
+	Address string
+	Status  string
+
+	// Kind is the script type of a decoded address, e.g. p2wpkh or p2sh
+	Kind string
+
+	// Network is the network a wrong network address belongs to
+	Network string
+
+	// Err explains why an invalid address was rejected
+	Err error
+}
+
+// CheckAddressFile validates one address per line of the file against the
+// network, skipping blank lines and # comments like a mnemonics file
+func CheckAddressFile(path string, params *chaincfg.Params) ([]AddressCheck, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening address file: %w", err)
+	}
+	defer file.Close()
+
+	return checkAddresses(file, params)
+}
+
+func checkAddresses(r io.Reader, params *chaincfg.Params) ([]AddressCheck, error) {
+	var checks []AddressCheck
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		check := CheckAddress(text, params)
+		check.Line = line
+		checks = append(checks, check)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading address file: %w", err)
+	}
+
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("address file has no addresses")
+	}
+
+	return checks, nil
+}
+
+// CheckAddress validates an address of any type against the network. An
+// address that fails to decode there but is valid on another known network is
+// reported as such instead of invalid. Testnet and regtest share their base58
+// version bytes, so their legacy addresses are valid on both.
+func CheckAddress(address string, params *chaincfg.Params) AddressCheck {
+	check := AddressCheck{Address: address}
+
+	decoded, err := btcutil.DecodeAddress(address, params)
+	if err == nil && decoded.IsForNet(params) {
+		check.Status = AddressValid
+		check.Kind = addressKind(decoded)
+		return check
+	}
+
+	for _, name := range networkNames {
+		other := networks[name]
+		if other.Net == params.Net {
+			continue
+		}
+
+		if decoded, err := btcutil.DecodeAddress(address, other); err == nil && decoded.IsForNet(other) {
+			check.Status = AddressWrongNetwork
+			check.Kind = addressKind(decoded)
+			check.Network = name
+			return check
+		}
+	}
+
+	if err == nil {
+		err = fmt.Errorf("not a %s address", params.Name)
+	}
+
+	check.Status = AddressInvalid
+	check.Err = err
+	return check
+}
+
+// addressKind names the script type of a decoded address. A P2SH address does
+// not reveal whether it wraps a witness program.
+func addressKind(address btcutil.Address) string {
+	switch a := address.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return "p2pkh"
+	case *btcutil.AddressScriptHash:
+		return "p2sh"
+	case *btcutil.AddressWitnessPubKeyHash:
+		return "p2wpkh"
+	case *btcutil.AddressWitnessScriptHash:
+		return "p2wsh"
+	case *btcutil.AddressTaproot:
+		return "p2tr"
+	case *btcutil.AddressSegWit:
+		return fmt.Sprintf("witness v%d", a.WitnessVersion())
+	default:
+		return "unknown"
+	}
+}
+
+// WriteAddressChecks writes a summary of the counts per status followed by
+// the line of every invalid or wrong network address
+func WriteAddressChecks(w io.Writer, checks []AddressCheck) {
+	counts := make(map[string]int)
+	for _, check := range checks {
+		counts[check.Status]++
+	}
+
+	fmt.Fprintf(w, "%d addresses: %d valid, %d invalid, %d wrong network\n", len(checks), counts[AddressValid], counts[AddressInvalid], counts[AddressWrongNetwork])
+
+	for _, check := range checks {
+		switch check.Status {
+		case AddressInvalid:
+			fmt.Fprintf(w, "Line %d: %s invalid: %v\n", check.Line, check.Address, check.Err)
+		case AddressWrongNetwork:
+			fmt.Fprintf(w, "Line %d: %s is a %s %s address\n", check.Line, check.Address, check.Network, check.Kind)
+		}
+	}
+}
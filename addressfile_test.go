@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestAddressFile validates a file mixing testnet address types with a
+// regtest address, a corrupted one and comments against testnet, which every
+// build includes
+func TestAddressFile(t *testing.T) {
+	lines := []string{"# allowlist", ""}
+	for _, network := range []*chaincfg.Params{&chaincfg.TestNet3Params, &chaincfg.RegressionNetParams} {
+		wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", network)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, typ := range []AddressType{AddressP2WPKHInP2SH, AddressTaproot} {
+			address, err := wallet.DeriveAddress(typ, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, address.EncodeAddress())
+		}
+	}
+
+	// Replace the regtest P2SH address, which is valid on testnet too, with a
+	// corrupted testnet Taproot address
+	corrupted := []byte(lines[3])
+	corrupted[len(corrupted)-1] ^= 1
+	lines[4] = string(corrupted)
+
+	checks, err := checkAddresses(strings.NewReader(strings.Join(lines, "\n")), &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, check := range checks {
+		got = append(got, fmt.Sprintf("%d %s %s %s", check.Line, check.Status, check.Kind, check.Network))
+	}
+
+	expected := []string{"3 valid p2sh ", "4 valid p2tr ", "5 invalid  ", "6 wrong network p2tr regtest"}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("address file checks %q, expected %q", got, expected)
+	}
+}
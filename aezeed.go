@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"slices"
+	"strings"
+	"time"
+
+	"btc-wallet/internal/aez"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Parameters of version 0 of the aezeed cipher seed of lnd, see
+// https://github.com/lightningnetwork/lnd/tree/master/aezeed. The enciphered
+// seed is version || ciphertext || salt || checksum, 33 bytes in 24 words.
+const (
+	aezeedVersion     = 0
+	aezeedEntropySize = 16
+	aezeedPlainSize   = 1 + 2 + aezeedEntropySize
+	aezeedExpansion   = 4
+	aezeedSaltSize    = 5
+	aezeedSize        = 1 + aezeedPlainSize + aezeedExpansion + aezeedSaltSize + 4
+	aezeedSaltOffset  = aezeedSize - 4 - aezeedSaltSize
+	aezeedSumOffset   = aezeedSize - 4
+	aezeedWordCount   = aezeedSize * 8 / 11
+)
+
+// aezeedDefaultPassphrase enciphers seeds created without a passphrase, as in lnd
+const aezeedDefaultPassphrase = "aezeed"
+
+// aezeedScryptN is the scrypt cost of version 0, lowered by the tests to
+// check the vectors of lnd, which were made with a cost of 16
+var aezeedScryptN = 32768
+
+// bitcoinGenesis is the timestamp of the genesis block, aezeed birthdays count
+// the days since
+var bitcoinGenesis = time.Unix(1231006505, 0)
+
+// ErrAezeedPassphrase is returned when an aezeed does not decipher under the
+// passphrase given. The checksum already matched, so the words are right.
+var ErrAezeedPassphrase = errors.New("invalid aezeed passphrase")
+
+// Aezeed is an lnd cipher seed. Unlike a BIP-39 mnemonic it carries a
+// birthday, and lnd uses its 16 bytes of entropy directly as the BIP-32 seed,
+// so an aezeed and a mnemonic never restore the same keys.
+type Aezeed struct {
+	Entropy  [aezeedEntropySize]byte
+	Birthday uint16
+
+	// salt of the scrypt key of the passphrase, drawn for each new seed and
+	// kept from a parsed one so it enciphers to the same words again
+	salt [aezeedSaltSize]byte
+}
+
+// AezeedBirthday returns the days from the genesis block to birthday, which
+// must fit the 16 bits of an aezeed
+func AezeedBirthday(birthday time.Time) (uint16, error) {
+	days := birthday.Sub(bitcoinGenesis) / (24 * time.Hour)
+	if birthday.Before(bitcoinGenesis) || days > 0xffff {
+		return 0, fmt.Errorf("aezeed birthday %s is not between the genesis block and %s", birthday.Format(time.DateOnly), bitcoinGenesis.Add(0xffff*24*time.Hour).Format(time.DateOnly))
+	}
+
+	return uint16(days), nil
+}
+
+// NewAezeed creates an aezeed of the entropy born on the day of birthday
+func NewAezeed(entropy [aezeedEntropySize]byte, birthday time.Time) (*Aezeed, error) {
+	days, err := AezeedBirthday(birthday)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := &Aezeed{Entropy: entropy, Birthday: days}
+	if _, err := rand.Read(seed.salt[:]); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+
+	return seed, nil
+}
+
+// BirthdayTime returns the day the seed was created
+func (s *Aezeed) BirthdayTime() time.Time {
+	return bitcoinGenesis.Add(time.Duration(s.Birthday) * 24 * time.Hour)
+}
+
+// aezeedKey stretches the passphrase into the aez key under the salt
+func aezeedKey(passphrase string, salt []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		passphrase = aezeedDefaultPassphrase
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, aezeedScryptN, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving aezeed key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Mnemonic enciphers the seed under the passphrase into its 24 words. An
+// empty passphrase is replaced by "aezeed" as lnd does.
+func (s *Aezeed) Mnemonic(passphrase string) (string, error) {
+	key, err := aezeedKey(passphrase, s.salt[:])
+	if err != nil {
+		return "", err
+	}
+
+	var plain [aezeedPlainSize]byte
+	plain[0] = aezeedVersion
+	binary.BigEndian.PutUint16(plain[1:], s.Birthday)
+	copy(plain[3:], s.Entropy[:])
+	defer clear(plain[:])
+
+	// The version and salt are authenticated as associated data
+	ad := append([]byte{aezeedVersion}, s.salt[:]...)
+
+	var data [aezeedSize]byte
+	data[0] = aezeedVersion
+	copy(data[1:], aez.Encrypt(key, nil, [][]byte{ad}, aezeedExpansion, plain[:], nil))
+	copy(data[aezeedSaltOffset:], s.salt[:])
+	binary.BigEndian.PutUint32(data[aezeedSumOffset:], crc32.Checksum(data[:aezeedSumOffset], crc32.MakeTable(crc32.Castagnoli)))
+
+	// Each word encodes the next 11 bits of the enciphered seed
+	words := make([]string, 0, aezeedWordCount)
+	var acc uint32
+	var bits int
+	for _, b := range data {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		if bits >= 11 {
+			bits -= 11
+			words = append(words, wordlists.English[acc>>bits&0x7ff])
+		}
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// ParseAezeed deciphers the 24 words of an aezeed under the passphrase, see
+// normalizeMnemonic for the spellings accepted
+func ParseAezeed(mnemonic string, passphrase string) (*Aezeed, error) {
+	words := strings.Fields(normalizeMnemonic(mnemonic))
+	if len(words) != aezeedWordCount {
+		return nil, fmt.Errorf("aezeed has %d words, expected %d", len(words), aezeedWordCount)
+	}
+
+	var data [aezeedSize]byte
+	var acc uint32
+	var bits, n int
+	for i, word := range words {
+		index := slices.Index(wordlists.English, word)
+		if index < 0 {
+			return nil, fmt.Errorf("word %d %q is not in the aezeed word list", i+1, word)
+		}
+
+		acc = acc<<11 | uint32(index)
+		bits += 11
+		for bits >= 8 {
+			bits -= 8
+			data[n] = byte(acc >> bits)
+			n++
+		}
+	}
+
+	if data[0] != aezeedVersion {
+		return nil, fmt.Errorf("unsupported aezeed version %d", data[0])
+	}
+
+	if crc32.Checksum(data[:aezeedSumOffset], crc32.MakeTable(crc32.Castagnoli)) != binary.BigEndian.Uint32(data[aezeedSumOffset:]) {
+		return nil, fmt.Errorf("aezeed checksum mismatch, a word is wrong")
+	}
+
+	var seed Aezeed
+	copy(seed.salt[:], data[aezeedSaltOffset:aezeedSumOffset])
+
+	key, err := aezeedKey(passphrase, seed.salt[:])
+	if err != nil {
+		return nil, err
+	}
+
+	ad := append([]byte{aezeedVersion}, seed.salt[:]...)
+	plain, ok := aez.Decrypt(key, nil, [][]byte{ad}, aezeedExpansion, data[1:aezeedSaltOffset], nil)
+	if !ok {
+		return nil, ErrAezeedPassphrase
+	}
+	defer clear(plain)
+
+	if plain[0] != aezeedVersion {
+		return nil, fmt.Errorf("unsupported aezeed seed version %d", plain[0])
+	}
+
+	seed.Birthday = binary.BigEndian.Uint16(plain[1:])
+	copy(seed.Entropy[:], plain[3:])
+
+	return &seed, nil
+}
+
+// NewWalletFromAezeed builds the wallet lnd restores from the aezeed: its
+// entropy is the BIP-32 seed and the enciphered words take the place of the
+// mnemonic
+func NewWalletFromAezeed(seed *Aezeed, passphrase string, params *chaincfg.Params) (*Wallet, error) {
+	mnemonic, err := seed.Mnemonic(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	masterKey, err := hdkeychain.NewMaster(seed.Entropy[:], params)
+	if err != nil {
+		return nil, fmt.Errorf("error generating master key: %w", err)
+	}
+
+	// Memoize the public key for concurrent derivations, see
+	// NewWalletFromEntropy
+	if _, err := masterKey.ECPubKey(); err != nil {
+		return nil, fmt.Errorf("error getting master public key: %w", err)
+	}
+
+	return &Wallet{
+		Entropy:   bytes.Clone(seed.Entropy[:]),
+		Mnemonic:  mnemonic,
+		Seed:      bytes.Clone(seed.Entropy[:]),
+		MasterKey: masterKey,
+		Params:    params,
+		Aezeed:    seed,
+	}, nil
+}
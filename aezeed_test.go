@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// aezeedVector is a version 0 test vector of lnd's aezeed package
+type aezeedVector struct {
+	birthday   time.Time
+	passphrase string
+	mnemonic   string
+	days       uint16
+}
+
+var (
+	aezeedTestEntropy = [aezeedEntropySize]byte{0x81, 0xb6, 0x37, 0xd8, 0x63, 0x59, 0xe6, 0x96, 0x0d, 0xe7, 0x95, 0xe4, 0x1e, 0x0b, 0x4c, 0xfd}
+	aezeedTestSalt    = [aezeedSaltSize]byte{'s', 'a', 'l', 't', '1'}
+
+	aezeedVectors = []aezeedVector{
+		{
+			birthday: bitcoinGenesis,
+			mnemonic: "ability liquid travel stem barely drastic pact cupboard apple thrive morning oak feature tissue couch old math inform success suggest drink motion know royal",
+			days:     0,
+		},
+		{
+			birthday:   time.Unix(1521799345, 0),
+			passphrase: "!very_safe_55345_password*",
+			mnemonic:   "able tree stool crush transfer cloud cross three profit outside hen citizen plate ride require leg siren drum success suggest drink require fiscal upgrade",
+			days:       3365,
+		},
+	}
+)
+
+// TestAezeedVectors checks the words of the lnd test vectors, and that they
+// decipher to the same entropy and birthday, under the scrypt cost lnd
+// made them with
+func TestAezeedVectors(t *testing.T) {
+	defer func(n int) { aezeedScryptN = n }(aezeedScryptN)
+	aezeedScryptN = 16
+
+	for _, v := range aezeedVectors {
+		seed, err := NewAezeed(aezeedTestEntropy, v.birthday)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seed.salt = aezeedTestSalt
+
+		mnemonic, err := seed.Mnemonic(v.passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mnemonic != v.mnemonic {
+			t.Errorf("aezeed %q, expected %q", mnemonic, v.mnemonic)
+		}
+
+		parsed, err := ParseAezeed(v.mnemonic, v.passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Entropy != aezeedTestEntropy || parsed.Birthday != v.days {
+			t.Errorf("deciphered entropy %x born on day %d, expected %x on day %d", parsed.Entropy, parsed.Birthday, aezeedTestEntropy, v.days)
+		}
+	}
+}
+
+// TestAezeedRoundTrip checks a new aezeed wallet restores from its words
+// under the passphrase to the same seed, birthday and keys, which are those
+// of the raw entropy as in lnd
+func TestAezeedRoundTrip(t *testing.T) {
+	birthday := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	seed, err := NewAezeed([aezeedEntropySize]byte{1, 2, 3}, birthday)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wallet, err := NewWalletFromAezeed(seed, "hunter2", &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseAezeed(wallet.Mnemonic, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Entropy != seed.Entropy || parsed.Birthday != seed.Birthday {
+		t.Errorf("deciphered entropy %x born on day %d, expected %x on day %d", parsed.Entropy, parsed.Birthday, seed.Entropy, seed.Birthday)
+	}
+
+	restored, err := NewWalletFromAezeed(parsed, "hunter2", &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Mnemonic != wallet.Mnemonic {
+		t.Errorf("restored aezeed %q, expected %q", restored.Mnemonic, wallet.Mnemonic)
+	}
+
+	master, err := hdkeychain.NewMaster(seed.Entropy[:], &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.MasterKey.String() != master.String() {
+		t.Errorf("master key %s, expected the key of the raw entropy %s", restored.MasterKey, master)
+	}
+
+	secret, err := restored.SeedSecret(SeedFormatAezeed)
+	if err != nil || secret != wallet.Mnemonic {
+		t.Errorf("aezeed secret %q (%v), expected %q", secret, err, wallet.Mnemonic)
+	}
+
+	if _, err := ParseAezeed(wallet.Mnemonic, "hunter3"); !errors.Is(err, ErrAezeedPassphrase) {
+		t.Errorf("error %v under the wrong passphrase, expected %v", err, ErrAezeedPassphrase)
+	}
+
+	words := strings.Fields(wallet.Mnemonic)
+	words[5], words[6] = words[6], words[5]
+	if _, err := ParseAezeed(strings.Join(words, " "), "hunter2"); err == nil || errors.Is(err, ErrAezeedPassphrase) {
+		t.Errorf("error %v for swapped words, expected a checksum mismatch", err)
+	}
+
+	if _, err := NewAezeed(seed.Entropy, bitcoinGenesis.Add(-time.Hour)); err == nil {
+		t.Error("accepted a birthday before the genesis block")
+	}
+}
+
+// TestValidateAezeed checks -seed-format aezeed refuses the BIP-39 inputs and
+// its own flags are refused without it
+func TestValidateAezeed(t *testing.T) {
+	for _, v := range []struct {
+		name string
+		args []string
+		ok   bool
+	}{
+		{"new", []string{"-seed-format", "aezeed", "-aezeed-birthday", "2024-05-01"}, true},
+		{"bip39 passphrase", []string{"-seed-format", "aezeed", "-passphrase", "TREZOR"}, false},
+		{"words", []string{"-seed-format", "aezeed", "-words", "24"}, false},
+		{"invalid birthday", []string{"-seed-format", "aezeed", "-aezeed-birthday", "2008-01-01"}, false},
+		{"restored birthday", []string{"-seed-format", "aezeed", "-mnemonic", aezeedVectors[0].mnemonic, "-aezeed-birthday", "2024-05-01"}, false},
+		{"without aezeed", []string{"-aezeed-passphrase", "hunter2"}, false},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			c, err := parseGenerateFlags("generate", append([]string{"-network", "testnet"}, v.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.validate(); (err == nil) != v.ok {
+				t.Errorf("error %v, expected ok %t", err, v.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// AuditResult is the checksum validity and master fingerprint of a mnemonic
+type AuditResult struct {
+	Line        int
+	Fingerprint string
+
+	// Err explains why an invalid mnemonic was rejected
+	Err error
+}
+
+// AuditMnemonic checks the mnemonic and computes its master fingerprint
+// without deriving any address. The fingerprint depends on the passphrase.
+func AuditMnemonic(mnemonic string, passphrase string, params *chaincfg.Params) AuditResult {
+	wallet, err := NewWalletFromMnemonic(mnemonic, passphrase, params)
+	if err != nil {
+		return AuditResult{Err: err}
+	}
+
+	fingerprint, err := wallet.Fingerprint()
+	if err != nil {
+		return AuditResult{Err: err}
+	}
+
+	return AuditResult{Fingerprint: hex.EncodeToString(fingerprint)}
+}
+
+// WriteAudit writes one line per result, prefixed with its line number for
+// mnemonics read from a file. The mnemonics themselves are never written.
+func WriteAudit(w io.Writer, results []AuditResult) {
+	for _, result := range results {
+		prefix := ""
+		if result.Line > 0 {
+			prefix = fmt.Sprintf("Line %d: ", result.Line)
+		}
+
+		if result.Err != nil {
+			fmt.Fprintf(w, "%sinvalid: %v\n", prefix, result.Err)
+			continue
+		}
+
+		fmt.Fprintf(w, "%svalid, fingerprint %s\n", prefix, result.Fingerprint)
+	}
+}
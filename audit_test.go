@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestAudit checks the audit fingerprint of the BIP-86 test vector mnemonic,
+// that a bad checksum is reported, and that the report omits the mnemonics
+func TestAudit(t *testing.T) {
+	bad := strings.Replace(bip86Mnemonic, "about", "abandon", 1)
+
+	results := []AuditResult{
+		AuditMnemonic(bip86Mnemonic, "", &chaincfg.MainNetParams),
+		AuditMnemonic(bad, "", &chaincfg.MainNetParams),
+	}
+
+	if results[0].Err != nil || results[0].Fingerprint != "73c5da0a" {
+		t.Fatalf("audit reported %+v, expected fingerprint 73c5da0a", results[0])
+	}
+
+	if results[1].Err == nil {
+		t.Fatalf("audit accepted a mnemonic with a bad checksum")
+	}
+
+	var out bytes.Buffer
+	WriteAudit(&out, results)
+
+	if strings.Contains(out.String(), "abandon") {
+		t.Fatalf("audit report contains a mnemonic")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// benchWallet restores the BIP-86 test vector mnemonic for the derivation
+// benchmarks, so they time ECC without the PBKDF2 of generation
+func benchWallet(b *testing.B) *Wallet {
+	b.Helper()
+
+	w, err := NewWalletFromMnemonic(bip86Mnemonic, "", &chaincfg.MainNetParams)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return w
+}
+
+// BenchmarkNewWallet times wallet generation, dominated by PBKDF2
+func BenchmarkNewWallet(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := NewWallet(128, "", &chaincfg.MainNetParams); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkDerive times deriving the first receive address of typ
+func benchmarkDerive(b *testing.B, typ AddressType) {
+	w := benchWallet(b)
+
+	for i := 0; i < b.N; i++ {
+		if _, err := w.DeriveAddress(typ, 0, 0); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDeriveP2PKH(b *testing.B) { benchmarkDerive(b, AddressP2PKH) }
+
+func BenchmarkDeriveP2WPKHInP2SH(b *testing.B) { benchmarkDerive(b, AddressP2WPKHInP2SH) }
+
+func BenchmarkDeriveP2WPKH(b *testing.B) { benchmarkDerive(b, AddressP2WPKH) }
+
+func BenchmarkDeriveTaproot(b *testing.B) { benchmarkDerive(b, AddressTaproot) }
+
+// BenchmarkDeriveAll times deriving every address type of an index, cold
+// from the master key and with the chain nodes cached
+func BenchmarkDeriveAll(b *testing.B) {
+	w := benchWallet(b)
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			// A fresh wallet of the same key has no cached chain nodes
+			cold := &Wallet{MasterKey: w.MasterKey, Params: w.Params}
+			if _, err := cold.DeriveAll(0, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := w.DeriveAll(0, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// PaymentRequest holds the optional query parameters of a BIP-21 URI, zero
+// values are left out
+type PaymentRequest struct {
+	Amount btcutil.Amount
+	Label  string
+}
+
+// URI returns the BIP-21 URI requesting payment to the address, e.g.
+// bitcoin:bc1q...?amount=0.001&label=Cold%20storage
+func (r PaymentRequest) URI(address btcutil.Address) string {
+	var params []string
+	if r.Amount > 0 {
+		params = append(params, "amount="+formatBTC(r.Amount))
+	}
+	if len(r.Label) > 0 {
+		params = append(params, "label="+escapeBIP21(r.Label))
+	}
+
+	uri := "bitcoin:" + address.EncodeAddress()
+	if len(params) > 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+
+	return uri
+}
+
+// escapeBIP21 percent-encodes a query value. Spaces become %20 rather than +,
+// which BIP-21 does not define and some wallets show literally.
+func escapeBIP21(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// formatBTC formats the amount as a decimal BTC value without trailing zeros
+// or an exponent, as BIP-21 requires
+func formatBTC(amount btcutil.Amount) string {
+	whole := int64(amount) / btcutil.SatoshiPerBitcoin
+	fraction := int64(amount) % btcutil.SatoshiPerBitcoin
+	if fraction == 0 {
+		return strconv.FormatInt(whole, 10)
+	}
+
+	return fmt.Sprintf("%d.%s", whole, strings.TrimRight(fmt.Sprintf("%08d", fraction), "0"))
+}
+
+// ParseBIP21Amount parses a positive decimal BTC amount with at most eight
+// decimals, e.g. 0.001
+func ParseBIP21Amount(value string) (btcutil.Amount, error) {
+	whole, fraction, _ := strings.Cut(value, ".")
+	if len(whole) == 0 && len(fraction) == 0 {
+		return 0, fmt.Errorf("invalid amount %q: must be a decimal BTC value such as 0.001", value)
+	}
+
+	for _, part := range []string{whole, fraction} {
+		if strings.Trim(part, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid amount %q: must be a decimal BTC value such as 0.001", value)
+		}
+	}
+
+	if len(fraction) > 8 {
+		return 0, fmt.Errorf("invalid amount %q: at most 8 decimals are allowed", value)
+	}
+
+	sats, err := strconv.ParseInt("0"+whole+fraction+strings.Repeat("0", 8-len(fraction)), 10, 64)
+	if err != nil || sats > btcutil.MaxSatoshi {
+		return 0, fmt.Errorf("invalid amount %q: exceeds the BTC supply", value)
+	}
+
+	if sats == 0 {
+		return 0, fmt.Errorf("invalid amount %q: must be positive", value)
+	}
+
+	return btcutil.Amount(sats), nil
+}
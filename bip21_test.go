@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestBIP21 checks the URI encoding of amounts and of labels with spaces and
+// reserved characters, and that malformed amounts are rejected
+func TestBIP21(t *testing.T) {
+	address, err := btcutil.DecodeAddress(bip84Address, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amount, err := ParseBIP21Amount("0.0012")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uris := map[string]PaymentRequest{
+		"bitcoin:" + bip84Address: {},
+		"bitcoin:" + bip84Address + "?amount=0.0012&label=Cold%20storage%20%26%20savings%3F": {Amount: amount, Label: "Cold storage & savings?"},
+		"bitcoin:" + bip84Address + "?amount=21":                                             {Amount: 21 * btcutil.SatoshiPerBitcoin},
+		"bitcoin:" + bip84Address + "?label=caf%C3%A9%2B1":                                   {Label: "café+1"},
+	}
+
+	for expected, request := range uris {
+		if uri := request.URI(address); uri != expected {
+			t.Fatalf("BIP-21 URI %s, expected %s", uri, expected)
+		}
+	}
+
+	for _, invalid := range []string{"", ".", "0", "-1", "1e3", "0.000000001", "1,5", "21000001"} {
+		if _, err := ParseBIP21Amount(invalid); err == nil {
+			t.Fatalf("BIP-21 amount %q was accepted", invalid)
+		}
+	}
+}
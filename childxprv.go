@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// ChildXprv returns the serialized extended private key of the address of
+// the type at index of the change chain, e.g. m/84'/0'/0'/0/5. It allows
+// spending from that address and must be handled like the mnemonic.
+func (w *Wallet) ChildXprv(t AddressType, change uint32, index uint32) (string, error) {
+	key, err := w.ExtendMasterKey(t.Purpose(), change, index)
+	if err != nil {
+		return "", fmt.Errorf("error extending master key: %w", err)
+	}
+
+	if !key.IsPrivate() {
+		return "", fmt.Errorf("watch-only wallet has no private keys")
+	}
+
+	return key.String(), nil
+}
+
+// ChildXprvs returns the child xprv of every type at index of the change chain
+// keyed by type
+func (w *Wallet) ChildXprvs(change uint32, index uint32) (map[AddressType]string, error) {
+	xprvs := make(map[AddressType]string)
+	for _, t := range AddressTypes {
+		xprv, err := w.ChildXprv(t, change, index)
+		if err != nil {
+			return nil, err
+		}
+
+		xprvs[t] = xprv
+	}
+
+	return xprvs, nil
+}
+
+// LeafAddress returns the address of the type paying to the key of a leaf
+// extended key, e.g. a parsed child xprv
+func (w *Wallet) LeafAddress(t AddressType, key *hdkeychain.ExtendedKey) (btcutil.Address, error) {
+	switch t {
+	case AddressP2PKH:
+		address, err := key.Address(w.Params)
+		if err != nil {
+			return nil, fmt.Errorf("error generating address: %w", err)
+		}
+
+		return address, nil
+	case AddressP2WPKHInP2SH:
+		witnessPubKeyHash, err := w.witnessPubKeyHash(key)
+		if err != nil {
+			return nil, err
+		}
+
+		script, err := nestedRedeemScript(witnessPubKeyHash)
+		if err != nil {
+			return nil, err
+		}
+
+		address, err := btcutil.NewAddressScriptHash(script, w.Params)
+		if err != nil {
+			return nil, fmt.Errorf("error generating P2SH address: %w", err)
+		}
+
+		return address, nil
+	case AddressP2WPKH:
+		return w.witnessPubKeyHash(key)
+	case AddressTaproot:
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("error getting public key: %w", err)
+		}
+
+		return w.DeriveTaprootAddress(0, 0, pubKey)
+	default:
+		return nil, fmt.Errorf("unknown address type %d", int(t))
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// TestChildXprvs checks that each exported child xprv re-parses and pays to
+// the address derived at its index
+func TestChildXprvs(t *testing.T) {
+	wallet := testWallet(t)
+
+	xprvs, err := wallet.ChildXprvs(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range AddressTypes {
+		key, err := hdkeychain.NewKeyFromString(xprvs[typ])
+		if err != nil {
+			t.Fatalf("%s: child xprv does not parse: %v", typ, err)
+		}
+
+		if !key.IsPrivate() {
+			t.Fatalf("%s: child xprv is not private", typ)
+		}
+
+		fromXprv, err := wallet.LeafAddress(typ, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		derived, err := wallet.DeriveAddress(typ, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if fromXprv.EncodeAddress() != derived.EncodeAddress() {
+			t.Fatalf("%s: child xprv pays to %s, expected %s", typ, fromXprv.EncodeAddress(), derived.EncodeAddress())
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// coldcardChain returns the chain code Coldcard uses for the network
+func coldcardChain(params *chaincfg.Params) (string, error) {
+	switch params.Name {
+	case chaincfg.MainNetParams.Name:
+		return "BTC", nil
+	case chaincfg.TestNet3Params.Name, chaincfg.SigNetParams.Name:
+		return "XTN", nil
+	case chaincfg.RegressionNetParams.Name:
+		return "XRT", nil
+	default:
+		return "", fmt.Errorf("network %s is not supported by Coldcard", params.Name)
+	}
+}
+
+// ColdcardAccount is the account entry of one address type in the generic
+// JSON export
+type ColdcardAccount struct {
+	Name  string `json:"name"`
+	Deriv string `json:"deriv"`
+	Xpub  string `json:"xpub"`
+	Desc  string `json:"desc"`
+	Pub   string `json:"_pub,omitempty"`
+	First string `json:"first"`
+}
+
+// ColdcardExport follows the skeleton of the Coldcard generic JSON wallet
+// export, which other signers and coordinators import as well
+type ColdcardExport struct {
+	Chain   string           `json:"chain"`
+	Xfp     string           `json:"xfp"`
+	Account int              `json:"account"`
+	Xpub    string           `json:"xpub"`
+	Bip44   *ColdcardAccount `json:"bip44"`
+	Bip49   *ColdcardAccount `json:"bip49"`
+	Bip84   *ColdcardAccount `json:"bip84"`
+	Bip86   *ColdcardAccount `json:"bip86"`
+}
+
+// ColdcardExport builds the generic JSON export of account 0 of every type.
+// It only contains public keys, the mnemonic is not included.
+func (w *Wallet) ColdcardExport() (*ColdcardExport, error) {
+	chain, err := coldcardChain(w.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := w.Fingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	masterXpub, err := w.MasterKey.Neuter()
+	if err != nil {
+		return nil, fmt.Errorf("error deriving master xpub: %w", err)
+	}
+
+	export := &ColdcardExport{
+		Chain: chain,
+		Xfp:   strings.ToUpper(hex.EncodeToString(fingerprint)),
+		Xpub:  masterXpub.String(),
+	}
+
+	for _, t := range AddressTypes {
+		account, err := w.coldcardAccount(t)
+		if err != nil {
+			return nil, fmt.Errorf("error exporting %s account: %w", t, err)
+		}
+
+		switch t {
+		case AddressP2PKH:
+			export.Bip44 = account
+		case AddressP2WPKHInP2SH:
+			export.Bip49 = account
+		case AddressP2WPKH:
+			export.Bip84 = account
+		case AddressTaproot:
+			export.Bip86 = account
+		}
+	}
+
+	return export, nil
+}
+
+func (w *Wallet) coldcardAccount(t AddressType) (*ColdcardAccount, error) {
+	account, err := w.AccountKey(t.Purpose())
+	if err != nil {
+		return nil, err
+	}
+
+	xpub, err := account.Neuter()
+	if err != nil {
+		return nil, fmt.Errorf("error deriving account xpub: %w", err)
+	}
+
+	desc, err := w.Descriptor(t, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := w.DeriveAddress(t, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &ColdcardAccount{
+		Name:  t.Name(),
+		Deriv: fmt.Sprintf("m/%d'/%d'/0'", t.Purpose(), w.Params.HDCoinType),
+		Xpub:  xpub.String(),
+		Desc:  desc,
+		First: first.EncodeAddress(),
+	}
+
+	// Nested and native SegWit accounts also carry the SLIP-132 ypub/zpub
+	if version, ok := SLIP132PublicVersion(t, w.Params); ok {
+		pub, err := xpub.CloneWithVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding SLIP-132 key: %w", err)
+		}
+
+		entry.Pub = pub.String()
+	}
+
+	return entry, nil
+}
+
+// WriteColdcardExport writes the Coldcard generic JSON export of a single wallet
+func WriteColdcardExport(fileName string, wallets []Generated) error {
+	if len(wallets) != 1 || wallets[0].Coldcard == nil {
+		return fmt.Errorf("the Coldcard export requires exactly one wallet")
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(wallets[0].Coldcard); err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	return nil
+}
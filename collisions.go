@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// AddressCollision is a first address derived by more than one mnemonic
+type AddressCollision struct {
+	Type    AddressType
+	Address string
+
+	// Lines are the mnemonics file lines deriving the address, in file order
+	Lines []int
+}
+
+// FindAddressCollisions derives the first receive address of every type for
+// each mnemonic and returns the addresses derived by more than one line.
+// Independent seeds never share an address, so any collision points at a
+// duplicated backup or a broken entropy source. Addresses of different types
+// are compared too, as a collision across types is just as impossible.
+func FindAddressCollisions(mnemonics []MnemonicLine, passphrase string, params *chaincfg.Params) ([]AddressCollision, error) {
+	type owner struct {
+		t     AddressType
+		lines []int
+	}
+
+	owners := make(map[string]*owner)
+	var order []string
+
+	for _, m := range mnemonics {
+		wallet, err := NewWalletFromMnemonic(m.Mnemonic, passphrase, params)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", m.Line, err)
+		}
+
+		set, err := wallet.DeriveAll(0, 0)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", m.Line, err)
+		}
+
+		for _, t := range AddressTypes {
+			address := set.Address(t).EncodeAddress()
+
+			o, ok := owners[address]
+			if !ok {
+				o = &owner{t: t}
+				owners[address] = o
+				order = append(order, address)
+			}
+
+			// A line is listed once even if it derived the address twice
+			if len(o.lines) == 0 || o.lines[len(o.lines)-1] != m.Line {
+				o.lines = append(o.lines, m.Line)
+			}
+		}
+	}
+
+	// Addresses are visited in the order first derived, so the collisions
+	// are ordered by their first line
+	var collisions []AddressCollision
+	for _, address := range order {
+		if o := owners[address]; len(o.lines) > 1 {
+			collisions = append(collisions, AddressCollision{Type: o.t, Address: address, Lines: o.lines})
+		}
+	}
+
+	return collisions, nil
+}
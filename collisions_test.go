@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestCollisions checks that distinct mnemonics derive no shared address and
+// that a mnemonic repeated with other spacing collides on every type
+func TestCollisions(t *testing.T) {
+	mnemonics := []MnemonicLine{
+		{Line: 1, Mnemonic: bip86Mnemonic},
+		{Line: 3, Mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+		{Line: 4, Mnemonic: "  " + strings.ReplaceAll(bip86Mnemonic, " ", "  ")},
+	}
+
+	collisions, err := FindAddressCollisions(mnemonics[:2], "", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collisions) != 0 {
+		t.Fatalf("distinct mnemonics reported %d collisions", len(collisions))
+	}
+
+	collisions, err = FindAddressCollisions(mnemonics, "", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collisions) != len(AddressTypes) {
+		t.Fatalf("repeated mnemonic reported %d collisions, expected %d", len(collisions), len(AddressTypes))
+	}
+
+	for _, c := range collisions {
+		if len(c.Lines) != 2 || c.Lines[0] != 1 || c.Lines[1] != 4 {
+			t.Fatalf("%s collision reported lines %v, expected [1 4]", c.Type.Name(), c.Lines)
+		}
+	}
+}
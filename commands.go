@@ -0,0 +1,921 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// command is a subcommand with its own flag set
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+// commands lists the subcommands in help order
+var commands = []command{
+	{"generate", "Generate new wallets (default)", func(args []string) error { return runGenerate("generate", args) }},
+	{"restore", "Restore a wallet from -mnemonic", func(args []string) error { return runGenerate("restore", args) }},
+	{"verify", "Check an address file, a signature or an xpub, or locate a known address of a mnemonic", runVerify},
+	{"sign", "Sign a 32 byte message with the key of a Taproot address", runSign},
+	{"psbt", "Build an unsigned PSBT spending a UTXO JSON file to an address", runPSBT},
+	{"derive", "Derive watch-only addresses from an account xpub, ypub or zpub", runDerive},
+	{"sweep", "Build a PSBT consolidating the funds of every derived address through an explorer", runSweep},
+	{"cosigner", "Print the BIP-48 multisig cosigner xpubs of a mnemonic", runCosigner},
+	{"discover", "Find the accounts of a mnemonic with on-chain history through an explorer", runDiscover},
+	{"scan", "Alias of discover", runDiscover},
+	{"split", "Split a mnemonic into two shares for separate backups", runSplit},
+	{"join", "Restore a mnemonic from the two shares of split", runJoin},
+	{"collisions", "Report first addresses shared by mnemonics of a file", runCollisions},
+}
+
+// run dispatches to the subcommand named by the first argument. Invocations
+// starting with a flag keep the flat flags of earlier versions and generate.
+func run(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runGenerate("generate", args)
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+
+	if args[0] == "help" {
+		fmt.Fprintln(os.Stderr, "Usage: btc-wallet [command] [flags]")
+		fmt.Fprintln(os.Stderr, "\nCommands:")
+		for _, c := range commands {
+			fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+		}
+		fmt.Fprintln(os.Stderr, "\nRun btc-wallet <command> -h for the flags of a command.")
+		return nil
+	}
+
+	return fmt.Errorf("unknown command %q, run help for the list of commands", args[0])
+}
+
+// logFlags are the logging flags shared by every command
+type logFlags struct {
+	level   *string
+	verbose *bool
+}
+
+func addLogFlags(fs *flag.FlagSet) logFlags {
+	return logFlags{
+		level:   fs.String("log-level", "info", "Log level: debug, info, warn or error"),
+		verbose: fs.Bool("verbose", false, "Enable debug logging, same as -log-level debug"),
+	}
+}
+
+// setup installs the default logger at the selected level
+func (f logFlags) setup() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*f.level)); err != nil {
+		return fmt.Errorf("invalid log level %q", *f.level)
+	}
+	if *f.verbose {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	return nil
+}
+
+// runVerify checks the addresses of a -verify-file against the network, a
+// Taproot -signature against its -address, or the -mnemonic against an account
+// -xpub, and otherwise finds the network and path of a known -address of the
+// -mnemonic wallet
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	var (
+		mnemonic       = fs.String("mnemonic", "", "Mnemonic of the wallet to search")
+		passphrase     = fs.String("passphrase", "", "Optional BIP-39 passphrase")
+		passphraseFile = fs.String("passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+		address        = fs.String("address", "", "Known address to locate in the -mnemonic wallet, or the signer of -signature")
+		gapLimit       = fs.Int("gap-limit", 20, "Count of address indices searched per type")
+		network        = fs.String("network", defaultNetwork, "Network of the -address of a -signature or the addresses of -verify-file")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+		message        = fs.String("message", "", "Hex encoded 32 byte message of -signature")
+		signature      = fs.String("signature", "", "Hex encoded BIP-340 signature to verify against the Taproot -address")
+		xpub           = fs.String("xpub", "", "Account xpub, ypub or zpub to check the -mnemonic against, e.g. from a hardware wallet")
+		purpose        = fs.Int("purpose", 84, "BIP-43 purpose of the -xpub account: 44, 49, 84 or 86")
+		account        = fs.Int("account", 0, "Account index of the -xpub")
+		verifyFile     = fs.String("verify-file", "", "Validate one address per line of a file against -network and report the invalid and wrong network lines")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if len(*verifyFile) > 0 {
+		params, err := NetworkParams(*network)
+		if err != nil {
+			return fmt.Errorf("error selecting network: %w", err)
+		}
+
+		if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+			return err
+		}
+
+		checks, err := CheckAddressFile(*verifyFile, params)
+		if err != nil {
+			return err
+		}
+
+		WriteAddressChecks(os.Stdout, checks)
+
+		failed := 0
+		for _, check := range checks {
+			if check.Status != AddressValid {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d addresses are not valid %s addresses", failed, len(checks), params.Name)
+		}
+
+		return nil
+	}
+
+	if len(*signature) > 0 {
+		params, err := NetworkParams(*network)
+		if err != nil {
+			return fmt.Errorf("error selecting network: %w", err)
+		}
+
+		if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+			return err
+		}
+
+		decoded, err := btcutil.DecodeAddress(*address, params)
+		if err != nil {
+			return fmt.Errorf("invalid -address: %w", err)
+		}
+
+		msg, err := ParseMessageHash(*message)
+		if err != nil {
+			return err
+		}
+
+		sig, err := hex.DecodeString(*signature)
+		if err != nil {
+			return fmt.Errorf("invalid -signature: %w", err)
+		}
+
+		if err := VerifyTaprootSignature(decoded, msg, sig); err != nil {
+			return err
+		}
+
+		fmt.Println("Signature valid")
+		return nil
+	}
+
+	if err := applySecretEnv(fs, true); err != nil {
+		return err
+	}
+
+	if len(*mnemonic) == 0 {
+		return fmt.Errorf("verify requires -mnemonic, -signature or -verify-file")
+	}
+
+	if err := resolvePassphrase(passphrase, *passphraseFile); err != nil {
+		return err
+	}
+
+	if len(*xpub) > 0 {
+		if *purpose < 0 || *account < 0 || int64(*account) >= hdkeychain.HardenedKeyStart {
+			return fmt.Errorf("invalid -purpose %d or -account %d", *purpose, *account)
+		}
+
+		_, params, err := xpubNetwork(*xpub)
+		if err != nil {
+			return fmt.Errorf("error checking xpub: %w", err)
+		}
+
+		if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+			return err
+		}
+
+		return verifyXPub(*mnemonic, *passphrase, *xpub, uint32(*purpose), uint32(*account))
+	}
+
+	if len(*address) == 0 {
+		return fmt.Errorf("verifying a -mnemonic requires -address or -xpub")
+	}
+
+	match, err := FindAddressNetwork(*mnemonic, *passphrase, *address, uint32(*gapLimit), *confirmMainnet)
+	if err != nil {
+		return fmt.Errorf("error finding address: %w", err)
+	}
+
+	fmt.Printf("Found %s address on network %s at path %s\n", match.Type, match.Network, match.Path)
+	return nil
+}
+
+// verifyXPub checks that the mnemonic derives the account xpub, and if not
+// reports which common passphrase, purpose or account mistake explains it
+func verifyXPub(mnemonic string, passphrase string, xpub string, bip uint32, account uint32) error {
+	ok, err := MnemonicMatchesXPub(mnemonic, passphrase, xpub, bip, account)
+	if err != nil {
+		return fmt.Errorf("error checking xpub: %w", err)
+	}
+
+	if ok {
+		fmt.Println("Mnemonic matches the xpub")
+		return nil
+	}
+
+	match, err := FindXPubMatch(mnemonic, passphrase, xpub, bip, account, 9)
+	if err != nil {
+		return fmt.Errorf("error checking xpub: %w", err)
+	}
+
+	if match == nil {
+		return fmt.Errorf("mnemonic does not match the xpub with any checked passphrase variant, purpose or account 0-9")
+	}
+
+	return fmt.Errorf("mnemonic does not match the xpub as given, but matches at %s with the passphrase %s", match.Path, match.Passphrase)
+}
+
+// runSign signs a message with the key-path key of a BIP-86 address
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	var (
+		mnemonic       = fs.String("mnemonic", "", "Mnemonic of the signing wallet")
+		passphrase     = fs.String("passphrase", "", "Optional BIP-39 passphrase")
+		passphraseFile = fs.String("passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+		network        = fs.String("network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+		index          = fs.Int("index", 0, "Index of the BIP-86 receive address to sign with")
+		message        = fs.String("message", "", "Hex encoded 32 byte message, e.g. a sighash")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if err := applySecretEnv(fs, true); err != nil {
+		return err
+	}
+
+	if len(*mnemonic) == 0 {
+		return fmt.Errorf("sign requires -mnemonic")
+	}
+
+	if *index < 0 || int64(*index) >= hdkeychain.HardenedKeyStart {
+		return fmt.Errorf("invalid -index %d", *index)
+	}
+
+	if err := resolvePassphrase(passphrase, *passphraseFile); err != nil {
+		return err
+	}
+
+	params, err := NetworkParams(*network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+		return err
+	}
+
+	msg, err := ParseMessageHash(*message)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := NewWalletFromMnemonic(*mnemonic, *passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	address, err := wallet.DeriveTaprootAddress(0, uint32(*index), nil)
+	if err != nil {
+		return err
+	}
+
+	signature, err := wallet.SignTaprootMessage(uint32(*index), msg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Address:", address)
+	fmt.Println("Signature:", hex.EncodeToString(signature))
+	return nil
+}
+
+// runPSBT builds an unsigned PSBT sweeping the UTXOs of a JSON file, less
+// the fee, to a single destination
+func runPSBT(args []string) error {
+	fs := flag.NewFlagSet("psbt", flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	var (
+		mnemonic       = fs.String("mnemonic", "", "Mnemonic of the wallet owning the UTXOs")
+		passphrase     = fs.String("passphrase", "", "Optional BIP-39 passphrase")
+		passphraseFile = fs.String("passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+		network        = fs.String("network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+		utxoFile       = fs.String("utxos", "", "JSON file of the UTXOs to spend")
+		to             = fs.String("to", "", "Destination address")
+		fee            = fs.Int64("fee", 0, "Absolute fee in satoshis")
+		out            = fs.String("out", "", "Binary PSBT output file (default base64 on stdout)")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if err := applySecretEnv(fs, true); err != nil {
+		return err
+	}
+
+	if len(*mnemonic) == 0 || len(*utxoFile) == 0 || len(*to) == 0 {
+		return fmt.Errorf("psbt requires -mnemonic, -utxos and -to")
+	}
+
+	if *fee <= 0 {
+		return fmt.Errorf("invalid -fee %d: must be positive", *fee)
+	}
+
+	if err := resolvePassphrase(passphrase, *passphraseFile); err != nil {
+		return err
+	}
+
+	params, err := NetworkParams(*network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+		return err
+	}
+
+	destination, err := btcutil.DecodeAddress(*to, params)
+	if err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+
+	destinationScript, err := txscript.PayToAddrScript(destination)
+	if err != nil {
+		return fmt.Errorf("error creating output script: %w", err)
+	}
+
+	utxos, err := LoadUTXOs(*utxoFile, params)
+	if err != nil {
+		return err
+	}
+
+	var total btcutil.Amount
+	for _, utxo := range utxos {
+		total += utxo.Amount
+	}
+
+	amount := total - btcutil.Amount(*fee)
+	output := wire.NewTxOut(int64(amount), destinationScript)
+	if amount <= 0 || mempool.IsDust(output, mempool.DefaultMinRelayTxFee) {
+		return fmt.Errorf("inputs of %v leave a dust output after the %v fee", total, btcutil.Amount(*fee))
+	}
+
+	wallet, err := NewWalletFromMnemonic(*mnemonic, *passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	packet, err := wallet.BuildPSBT(utxos, []*wire.TxOut{output})
+	if err != nil {
+		return fmt.Errorf("error building PSBT: %w", err)
+	}
+
+	slog.Info("built PSBT", "inputs", len(utxos), "amount", amount, "fee", btcutil.Amount(*fee))
+
+	return writePSBT(packet, *out)
+}
+
+// writePSBT saves the binary PSBT to out, or prints it as base64 if out is empty
+func writePSBT(packet *psbt.Packet, out string) error {
+	if len(out) > 0 {
+		file, err := os.Create(out)
+		if err != nil {
+			return fmt.Errorf("error creating file: %w", err)
+		}
+		defer file.Close()
+
+		if err := packet.Serialize(file); err != nil {
+			return fmt.Errorf("error writing PSBT: %w", err)
+		}
+
+		fmt.Println("Saved PSBT to:", out)
+		return nil
+	}
+
+	encoded, err := packet.B64Encode()
+	if err != nil {
+		return fmt.Errorf("error encoding PSBT: %w", err)
+	}
+
+	fmt.Println(encoded)
+	return nil
+}
+
+// runSweep builds an unsigned PSBT moving the funds of every derived address
+// found through an Esplora explorer to a single destination
+func runSweep(args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	var (
+		mnemonic       = fs.String("mnemonic", "", "Mnemonic of the wallet to sweep")
+		passphrase     = fs.String("passphrase", "", "Optional BIP-39 passphrase")
+		passphraseFile = fs.String("passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+		network        = fs.String("network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+		to             = fs.String("to", "", "Destination address")
+		feeRate        = fs.Int("fee-rate", 0, "Fee rate in satoshis per vbyte")
+		receiveGap     = fs.Int("receive-gap", 20, "Count of consecutive unused receive addresses ending the scan of the receive chain")
+		changeGap      = fs.Int("change-gap", 20, "Count of consecutive unused change addresses ending the scan of the change chain")
+		explorerURL    = fs.String("explorer", "", "Esplora API base URL (default the public explorer of the network)")
+		rateLimit      = fs.Float64("rate-limit", 0, "Maximum explorer requests per second, 0 for no limit; 429 responses are retried with backoff either way")
+		out            = fs.String("out", "", "Binary PSBT output file (default base64 on stdout)")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if err := applySecretEnv(fs, true); err != nil {
+		return err
+	}
+
+	if len(*mnemonic) == 0 || len(*to) == 0 {
+		return fmt.Errorf("sweep requires -mnemonic and -to")
+	}
+
+	if *feeRate < 1 {
+		return fmt.Errorf("invalid -fee-rate %d: must be at least 1 sat/vB", *feeRate)
+	}
+
+	if err := resolvePassphrase(passphrase, *passphraseFile); err != nil {
+		return err
+	}
+
+	params, err := NetworkParams(*network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+		return err
+	}
+
+	destination, err := btcutil.DecodeAddress(*to, params)
+	if err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+
+	baseURL := *explorerURL
+	if len(baseURL) == 0 {
+		baseURL, err = EsploraURL(params)
+		if err != nil {
+			return err
+		}
+	}
+
+	explorer, err := NewRateLimitedExplorer(baseURL, *rateLimit)
+	if err != nil {
+		return fmt.Errorf("invalid -rate-limit: %w", err)
+	}
+
+	wallet, err := NewWalletFromMnemonic(*mnemonic, *passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	packet, err := wallet.BuildSweep(explorer, destination, *feeRate, *receiveGap, *changeGap)
+	if err != nil {
+		return fmt.Errorf("error building sweep: %w", err)
+	}
+
+	return writePSBT(packet, *out)
+}
+
+// runDerive derives watch-only addresses from an account extended public key
+func runDerive(args []string) error {
+	fs := flag.NewFlagSet("derive", flag.ExitOnError)
+	logging := addLogFlags(fs)
+
+	var (
+		xpub           = fs.String("xpub", "", "Account xpub, ypub or zpub to derive from")
+		xpubType       = fs.String("type", "", "Address type of a plain xpub: p2pkh, p2sh-p2wpkh, p2wpkh or p2tr")
+		network        = fs.String("network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+		addresses      = fs.Int("addresses", 1, "Count of address indices to derive")
+		indices        = fs.String("indices", "", "Comma separated address indices to derive, e.g. 0,7,42")
+		change         = fs.Int("change", 0, "Chain to derive addresses from: 0 for receive, 1 for change, other values are non-standard")
+		sortOrder      = fs.String("sort", SortIndex, "Address order: index or addr (lexical)")
+		maxIndex       = fs.Int("max-index", defaultMaxIndex, "Highest -indices value derived without -force, deeper indices are usually typos")
+		force          = fs.Bool("force", false, "Allow indices above -max-index")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if len(*xpub) == 0 {
+		return fmt.Errorf("derive requires -xpub")
+	}
+
+	params, err := NetworkParams(*network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+		return err
+	}
+
+	if err := ValidateSort(*sortOrder); err != nil {
+		return err
+	}
+
+	if *addresses < 1 {
+		return fmt.Errorf("invalid address count %d: must be at least 1", *addresses)
+	}
+
+	if err := ValidateChange(*change); err != nil {
+		return err
+	}
+
+	var indexList []uint32
+	if len(*indices) > 0 {
+		indexList, err = ParseIndices(*indices)
+		if err != nil {
+			return fmt.Errorf("invalid -indices: %w", err)
+		}
+
+		if *maxIndex < 0 {
+			return fmt.Errorf("invalid -max-index %d: must not be negative", *maxIndex)
+		}
+
+		if err := CheckIndexThreshold(indexList, uint32(*maxIndex), *force); err != nil {
+			return err
+		}
+	}
+
+	return WriteWatchOnlyAddresses(os.Stdout, *xpub, *xpubType, params, uint32(*change), indexRange(indexList, *addresses), *sortOrder)
+}
+
+// runSplit splits -mnemonic into two shares for backups kept in separate
+// locations
+func runSplit(args []string) error {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	logging := addLogFlags(fs)
+
+	var (
+		mnemonic = fs.String("mnemonic", "", "Mnemonic to split")
+		scheme   = fs.String("scheme", SplitXOR, "Split scheme: xor (two random looking mnemonics) or naive (two halves of the words, insecure)")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if err := applySecretEnv(fs, true); err != nil {
+		return err
+	}
+
+	if len(*mnemonic) == 0 {
+		return fmt.Errorf("split requires -mnemonic")
+	}
+
+	shares, err := SplitMnemonic(*mnemonic, *scheme)
+	if err != nil {
+		return err
+	}
+
+	if *scheme == SplitNaive {
+		fmt.Fprintln(os.Stderr, NaiveSplitWarning)
+	}
+
+	for i, share := range shares {
+		fmt.Printf("Share %d: %s\n", i+1, share)
+	}
+
+	return nil
+}
+
+// runJoin restores a mnemonic from the two shares of split
+func runJoin(args []string) error {
+	fs := flag.NewFlagSet("join", flag.ExitOnError)
+	logging := addLogFlags(fs)
+
+	var (
+		share1 = fs.String("share1", "", "First share")
+		share2 = fs.String("share2", "", "Second share")
+		scheme = fs.String("scheme", SplitXOR, "Split scheme the shares were created with: xor or naive")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if len(*share1) == 0 || len(*share2) == 0 {
+		return fmt.Errorf("join requires -share1 and -share2")
+	}
+
+	mnemonic, err := JoinMnemonic([2]string{*share1, *share2}, *scheme)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Mnemonic:", mnemonic)
+
+	return nil
+}
+
+// runCosigner prints the BIP-48 cosigner keys of the mnemonic for setting
+// up a multisig wallet with other signers
+func runCosigner(args []string) error {
+	fs := flag.NewFlagSet("cosigner", flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	var (
+		mnemonic       = fs.String("mnemonic", "", "Mnemonic of the cosigner wallet")
+		passphrase     = fs.String("passphrase", "", "Optional BIP-39 passphrase")
+		passphraseFile = fs.String("passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+		network        = fs.String("network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+		account        = fs.Int("account", 0, "Account index")
+		scriptType     = fs.String("script-type", "", "BIP-48 script type: p2sh-p2wsh or p2wsh (default both)")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if err := applySecretEnv(fs, true); err != nil {
+		return err
+	}
+
+	if len(*mnemonic) == 0 {
+		return fmt.Errorf("cosigner requires -mnemonic")
+	}
+
+	if *account < 0 || int64(*account) >= hdkeychain.HardenedKeyStart {
+		return fmt.Errorf("invalid -account %d", *account)
+	}
+
+	scriptTypes := BIP48ScriptTypes
+	if len(*scriptType) > 0 {
+		parsed, err := ParseBIP48ScriptType(*scriptType)
+		if err != nil {
+			return fmt.Errorf("invalid -script-type: %w", err)
+		}
+		scriptTypes = []uint32{parsed}
+	}
+
+	if err := resolvePassphrase(passphrase, *passphraseFile); err != nil {
+		return err
+	}
+
+	params, err := NetworkParams(*network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+		return err
+	}
+
+	wallet, err := NewWalletFromMnemonic(*mnemonic, *passphrase, params)
+	if err != nil {
+		return fmt.Errorf("error restoring wallet: %w", err)
+	}
+
+	for _, t := range scriptTypes {
+		cosigner, err := wallet.Cosigner(uint32(*account), t)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%s cosigner: %s\n", BIP48ScriptTypeName(t), cosigner.KeyOrigin())
+	}
+
+	return nil
+}
+
+// runDiscover runs BIP-44 account discovery for -mnemonic against an Esplora
+// explorer and reports the accounts with history
+func runDiscover(args []string) error {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	var (
+		mnemonic       = fs.String("mnemonic", "", "Mnemonic of the wallet to discover")
+		passphrase     = fs.String("passphrase", "", "Optional BIP-39 passphrase")
+		passphraseFile = fs.String("passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+		network        = fs.String("network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+		addressType    = fs.String("type", "p2wpkh", "Address type to discover: p2pkh, p2sh-p2wpkh, p2wpkh or p2tr")
+		gapLimit       = fs.Int("gap-limit", 20, "Count of receive addresses scanned per account")
+		maxAccounts    = fs.Int("max-accounts", 20, "Maximum count of accounts scanned")
+		explorerURL    = fs.String("explorer", "", "Esplora API base URL (default the public explorer of the network)")
+		rateLimit      = fs.Float64("rate-limit", 0, "Maximum explorer requests per second, 0 for no limit; 429 responses are retried with backoff either way")
+		nextUnused     = fs.Int("next-unused", 0, "Print the next N receive addresses of -type in account 0 without history instead of discovering accounts")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if err := applySecretEnv(fs, true); err != nil {
+		return err
+	}
+
+	if len(*mnemonic) == 0 {
+		return fmt.Errorf("discover requires -mnemonic")
+	}
+
+	if *nextUnused < 0 {
+		return fmt.Errorf("invalid -next-unused %d: must be positive", *nextUnused)
+	}
+
+	if *maxAccounts < 1 {
+		return fmt.Errorf("invalid -max-accounts %d: must be at least 1", *maxAccounts)
+	}
+
+	t, err := ParseAddressType(*addressType)
+	if err != nil {
+		return fmt.Errorf("invalid -type: %w", err)
+	}
+
+	if err := resolvePassphrase(passphrase, *passphraseFile); err != nil {
+		return err
+	}
+
+	params, err := NetworkParams(*network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+		return err
+	}
+
+	baseURL := *explorerURL
+	if len(baseURL) == 0 {
+		baseURL, err = EsploraURL(params)
+		if err != nil {
+			return err
+		}
+	}
+
+	explorer, err := NewRateLimitedExplorer(baseURL, *rateLimit)
+	if err != nil {
+		return fmt.Errorf("invalid -rate-limit: %w", err)
+	}
+
+	wallet, err := NewWalletFromMnemonic(*mnemonic, *passphrase, params)
+	if err != nil {
+		return fmt.Errorf("error restoring wallet: %w", err)
+	}
+
+	if *nextUnused > 0 {
+		unused, err := wallet.NextUnusedAddresses(explorer, t.Purpose(), uint32(*nextUnused))
+		if err != nil {
+			return fmt.Errorf("error finding unused addresses: %w", err)
+		}
+
+		for _, address := range unused {
+			fmt.Printf("%s #%d: %s\n", wallet.DerivationPath(t.Purpose(), 0, address.Index), address.Index, address.Address)
+		}
+
+		return nil
+	}
+
+	active, err := wallet.DiscoverAccounts(explorer, t.Purpose(), *gapLimit, *maxAccounts)
+	if err != nil {
+		return fmt.Errorf("error discovering accounts: %w", err)
+	}
+
+	if len(active) == 0 {
+		fmt.Printf("No %s account has history in the first %d addresses\n", t, *gapLimit)
+		return nil
+	}
+
+	for _, activity := range active {
+		fmt.Printf("Account %d (%s): %d used addresses, %d transactions\n", activity.Account, activity.Path, len(activity.Used), activity.TxCount)
+	}
+
+	return nil
+}
+
+// runCollisions checks that the mnemonics of a file derive pairwise distinct
+// first addresses and reports the lines of any that do not
+func runCollisions(args []string) error {
+	fs := flag.NewFlagSet("collisions", flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	var (
+		mnemonicsFile  = fs.String("mnemonics-file", "", "File of one mnemonic per line, blank lines and # comments are skipped")
+		passphrase     = fs.String("passphrase", "", "Optional BIP-39 passphrase applied to every mnemonic")
+		passphraseFile = fs.String("passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+		network        = fs.String("network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+		confirmMainnet = fs.Bool("confirm-mainnet", false, "Confirm mainnet use when guarded by BTC_WALLET_ALLOW_MAINNET")
+	)
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return err
+	}
+
+	if err := applySecretEnv(fs, false); err != nil {
+		return err
+	}
+
+	if len(*mnemonicsFile) == 0 {
+		return fmt.Errorf("collisions requires -mnemonics-file")
+	}
+
+	if err := resolvePassphrase(passphrase, *passphraseFile); err != nil {
+		return err
+	}
+
+	params, err := NetworkParams(*network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if err := CheckMainnetAllowed(params, *confirmMainnet); err != nil {
+		return err
+	}
+
+	// Duplicates are kept, a repeated mnemonic is reported as a collision
+	mnemonics, err := ReadMnemonicLines(*mnemonicsFile)
+	if err != nil {
+		return err
+	}
+
+	collisions, err := FindAddressCollisions(mnemonics, *passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	if len(collisions) == 0 {
+		fmt.Printf("No collisions among the first addresses of %d mnemonics\n", len(mnemonics))
+		return nil
+	}
+
+	for _, c := range collisions {
+		lines := make([]string, len(c.Lines))
+		for i, line := range c.Lines {
+			lines[i] = fmt.Sprint(line)
+		}
+
+		fmt.Printf("%s %s derived by lines %s\n", c.Type.Name(), c.Address, strings.Join(lines, ", "))
+	}
+
+	return fmt.Errorf("%d addresses collide across %d mnemonics", len(collisions), len(mnemonics))
+}
@@ -0,0 +1,11 @@
+package main
+
+import "crypto/subtle"
+
+// SecureCompare reports whether a and b are equal in constant time.
+// Any comparison of secrets, MACs or signatures must go through this helper
+// rather than == or bytes.Equal so that timing does not leak how many
+// leading bytes matched.
+func SecureCompare(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
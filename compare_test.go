@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+// TestSecureCompare checks SecureCompare matches only equal byte strings,
+// including ones differing in length or only in their last byte
+func TestSecureCompare(t *testing.T) {
+	for _, v := range []struct {
+		name  string
+		a, b  []byte
+		equal bool
+	}{
+		{"equal", []byte("secret"), []byte("secret"), true},
+		{"empty", nil, []byte{}, true},
+		{"last byte", []byte("secret"), []byte("secreT"), false},
+		{"first byte", []byte("secret"), []byte("Secret"), false},
+		{"prefix", []byte("secret"), []byte("secre"), false},
+		{"empty and nonempty", nil, []byte("secret"), false},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			if equal := SecureCompare(v.a, v.b); equal != v.equal {
+				t.Errorf("SecureCompare(%q, %q) = %t, expected %t", v.a, v.b, equal, v.equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// coreWalletRange is the range of every imported descriptor, the size of
+// Bitcoin Core's default keypool
+const coreWalletRange = 1000
+
+// CoreImportRequest is one request of Bitcoin Core's importdescriptors RPC
+// activating a descriptor of the wallet
+type CoreImportRequest struct {
+	Desc      string    `json:"desc"`
+	Active    bool      `json:"active"`
+	Range     [2]uint32 `json:"range"`
+	NextIndex uint32    `json:"next_index"`
+	Internal  bool      `json:"internal"`
+
+	// Timestamp is "now" or the Unix time Core rescans the chain from
+	Timestamp any `json:"timestamp"`
+}
+
+// checkCoreNetwork returns an error unless Bitcoin Core runs the network, a
+// signet with a custom HRP derives addresses Core does not
+func checkCoreNetwork(params *chaincfg.Params) error {
+	for _, core := range []*chaincfg.Params{&chaincfg.MainNetParams, &chaincfg.TestNet3Params, &chaincfg.SigNetParams, &chaincfg.RegressionNetParams} {
+		if params.Net == core.Net && params.Bech32HRPSegwit == core.Bech32HRPSegwit {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("network %s is not supported by Bitcoin Core", params.Name)
+}
+
+// CoreWallet builds the importdescriptors requests activating the receive and
+// change descriptors of each type in a watch-only Bitcoin Core descriptor
+// wallet. A new wallet has no history and is imported with the timestamp
+// "now", a restored one with 0 so Core rescans the whole chain.
+func (w *Wallet) CoreWallet(types []AddressType, restored bool) ([]CoreImportRequest, error) {
+	if err := checkCoreNetwork(w.Params); err != nil {
+		return nil, err
+	}
+
+	descriptors, err := w.Descriptors(types)
+	if err != nil {
+		return nil, err
+	}
+
+	var timestamp any = "now"
+	if restored {
+		timestamp = 0
+	}
+
+	requests := make([]CoreImportRequest, 0, len(descriptors))
+	for _, d := range descriptors {
+		requests = append(requests, CoreImportRequest{
+			Desc:      d.Descriptor,
+			Active:    true,
+			Range:     [2]uint32{0, coreWalletRange - 1},
+			NextIndex: 0,
+			Internal:  d.Change == 1,
+			Timestamp: timestamp,
+		})
+	}
+
+	return requests, nil
+}
+
+// WriteCoreWallet writes the importdescriptors requests of a single wallet
+// as the JSON array bitcoin-cli importdescriptors takes
+func WriteCoreWallet(fileName string, wallets []Generated) error {
+	if len(wallets) != 1 || wallets[0].CoreWallet == nil {
+		return fmt.Errorf("the Bitcoin Core wallet export requires exactly one wallet")
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(wallets[0].CoreWallet); err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	return nil
+}
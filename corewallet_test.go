@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestCoreWallet validates the Bitcoin Core wallet export against the
+// importdescriptors request schema and checks the timestamp of restored
+// wallets and the networks Core does not run
+func TestCoreWallet(t *testing.T) {
+	wallet := testWallet(t)
+
+	for _, restored := range []bool{false, true} {
+		requests, err := wallet.CoreWallet(AddressTypes, restored)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := json.Marshal(requests)
+		if err != nil {
+			t.Fatalf("error encoding JSON: %v", err)
+		}
+
+		if err := validateImportDescriptors(data); err != nil {
+			t.Fatalf("invalid importdescriptors request: %v", err)
+		}
+
+		if len(requests) != 2*len(AddressTypes) {
+			t.Fatalf("%d importdescriptors requests, expected %d", len(requests), 2*len(AddressTypes))
+		}
+
+		expected := `"now"`
+		if restored {
+			expected = "0"
+		}
+		for _, request := range requests {
+			if timestamp, _ := json.Marshal(request.Timestamp); string(timestamp) != expected {
+				t.Fatalf("timestamp %s of a restored (%t) wallet, expected %s", timestamp, restored, expected)
+			}
+		}
+	}
+
+	for _, invalid := range []string{
+		`[{"desc": "wpkh([73c5da0a/84'/0'/0']xpub/0/*)#00000000", "timestamp": "now"}]`,
+		`[{"desc": "addr(bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu)#lpewvaaa", "active": true, "timestamp": "now"}]`,
+		`[{"desc": "addr(bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu)#lpewvaaa", "timestamp": "yesterday"}]`,
+	} {
+		if err := validateImportDescriptors([]byte(invalid)); err == nil {
+			t.Fatalf("invalid importdescriptors request accepted: %s", invalid)
+		}
+	}
+
+	signet, err := SignetWithHRP("sbtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, params := range []*chaincfg.Params{signet, &chaincfg.MainNetParams} {
+		w, err := NewWalletFromMnemonic(bip86Mnemonic, "", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = w.CoreWallet(AddressTypes, false)
+		if err != nil && params == &chaincfg.MainNetParams {
+			t.Fatalf("Bitcoin Core wallet export refused on mainnet: %v", err)
+		}
+		if err == nil && params == signet {
+			t.Fatalf("Bitcoin Core wallet export accepted for a signet with a custom HRP")
+		}
+	}
+}
+
+// validateImportDescriptors checks the requests of data against the
+// importdescriptors RPC of Bitcoin Core: the known keys, a checksummed desc,
+// a timestamp of "now" or a Unix time, a range of at most 1000000 indices
+// with next_index inside it, active only for ranged descriptors, no label on
+// active ones and a single active descriptor per address type and chain
+func validateImportDescriptors(data []byte) error {
+	var requests []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &requests); err != nil {
+		return fmt.Errorf("not an array of request objects: %w", err)
+	}
+
+	if len(requests) == 0 {
+		return fmt.Errorf("no requests")
+	}
+
+	allowed := []string{"desc", "active", "range", "next_index", "timestamp", "internal", "label"}
+	activeChains := make(map[string]int)
+	for i, request := range requests {
+		for key := range request {
+			if !slices.Contains(allowed, key) {
+				return fmt.Errorf("request %d: unknown key %q", i, key)
+			}
+		}
+
+		var desc string
+		if err := json.Unmarshal(request["desc"], &desc); err != nil {
+			return fmt.Errorf("request %d: desc must be a string", i)
+		}
+
+		body, checksum, ok := strings.Cut(desc, "#")
+		if !ok {
+			return fmt.Errorf("request %d: desc has no checksum", i)
+		}
+		if expected, err := DescriptorChecksum(body); err != nil || checksum != expected {
+			return fmt.Errorf("request %d: desc checksum %s, expected %s", i, checksum, expected)
+		}
+
+		timestamp, ok := request["timestamp"]
+		if !ok {
+			return fmt.Errorf("request %d: missing timestamp", i)
+		}
+		var unix int64
+		if string(timestamp) != `"now"` && (json.Unmarshal(timestamp, &unix) != nil || unix < 0) {
+			return fmt.Errorf("request %d: timestamp %s must be \"now\" or a Unix time", i, timestamp)
+		}
+
+		var active, internal bool
+		for key, value := range map[string]*bool{"active": &active, "internal": &internal} {
+			if raw, ok := request[key]; ok {
+				if err := json.Unmarshal(raw, value); err != nil {
+					return fmt.Errorf("request %d: %s must be a boolean", i, key)
+				}
+			}
+		}
+
+		ranged := strings.Contains(body, "*")
+		if raw, ok := request["range"]; ok {
+			if !ranged {
+				return fmt.Errorf("request %d: range given for an unranged descriptor", i)
+			}
+
+			var bounds [2]int64
+			var end int64
+			if json.Unmarshal(raw, &end) == nil {
+				bounds[1] = end
+			} else if err := json.Unmarshal(raw, &bounds); err != nil {
+				return fmt.Errorf("request %d: range must be an end or a [begin, end] pair", i)
+			}
+
+			if bounds[0] < 0 || bounds[1] < bounds[0] || bounds[1] >= hdkeychain.HardenedKeyStart || bounds[1]-bounds[0] >= 1000000 {
+				return fmt.Errorf("request %d: invalid range %s", i, raw)
+			}
+
+			if raw, ok := request["next_index"]; ok {
+				var next int64
+				if err := json.Unmarshal(raw, &next); err != nil || next < bounds[0] || next > bounds[1] {
+					return fmt.Errorf("request %d: next_index %s outside the range %s", i, raw, request["range"])
+				}
+			}
+		}
+
+		if active {
+			if !ranged {
+				return fmt.Errorf("request %d: only ranged descriptors can be active", i)
+			}
+
+			if _, ok := request["label"]; ok {
+				return fmt.Errorf("request %d: active descriptors cannot have a label", i)
+			}
+
+			// Core keeps one active descriptor per output type and chain
+			chain := fmt.Sprintf("%s internal=%t", body[:strings.Index(body, "(")], internal)
+			activeChains[chain]++
+			if activeChains[chain] > 1 {
+				return fmt.Errorf("request %d: second active %s descriptor", i, chain)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// BIP-48 script types, the fourth hardened level of m/48'/coin'/account'/script_type'
+const (
+	BIP48P2SHP2WSH uint32 = 1
+	BIP48P2WSH     uint32 = 2
+)
+
+// BIP48ScriptTypes lists the script types in the order cosigners are printed
+var BIP48ScriptTypes = []uint32{BIP48P2SHP2WSH, BIP48P2WSH}
+
+// BIP48ScriptTypeName returns the name of the BIP-48 script type
+func BIP48ScriptTypeName(scriptType uint32) string {
+	switch scriptType {
+	case BIP48P2SHP2WSH:
+		return "p2sh-p2wsh"
+	case BIP48P2WSH:
+		return "p2wsh"
+	default:
+		return fmt.Sprintf("script type %d", scriptType)
+	}
+}
+
+// ParseBIP48ScriptType parses a script type name of BIP48ScriptTypeName
+func ParseBIP48ScriptType(name string) (uint32, error) {
+	for _, scriptType := range BIP48ScriptTypes {
+		if BIP48ScriptTypeName(scriptType) == name {
+			return scriptType, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown BIP-48 script type %q, expected p2sh-p2wsh or p2wsh", name)
+}
+
+// Cosigner is the key a wallet contributes to a BIP-48 multisig setup, as
+// hardware wallets export it
+type Cosigner struct {
+	ScriptType  uint32
+	Fingerprint string
+	Path        string
+	Xpub        string
+}
+
+// KeyOrigin returns the key with its origin as used in multisig descriptors,
+// e.g. [73c5da0a/48'/0'/0'/2']xpub...
+func (c Cosigner) KeyOrigin() string {
+	return fmt.Sprintf("[%s/%s]%s", c.Fingerprint, c.Path[len("m/"):], c.Xpub)
+}
+
+// CosignerKey derives the BIP-48 multisig node of the account and script
+// type: m/48'/coin'/account'/script_type'
+func (w *Wallet) CosignerKey(account uint32, scriptType uint32) (*hdkeychain.ExtendedKey, error) {
+	if scriptType != BIP48P2SHP2WSH && scriptType != BIP48P2WSH {
+		return nil, fmt.Errorf("invalid BIP-48 script type %d", scriptType)
+	}
+
+	accountKey, err := w.AccountKeyAt(48, account) // m/48'/0'/0'
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := accountKey.Derive(hdkeychain.HardenedKeyStart + scriptType) // m/48'/0'/0'/2'
+	if err != nil {
+		return nil, fmt.Errorf("error deriving script type: %w", err)
+	}
+
+	return key, nil
+}
+
+// Cosigner returns the public cosigner key of the account and script type
+// with its origin
+func (w *Wallet) Cosigner(account uint32, scriptType uint32) (Cosigner, error) {
+	key, err := w.CosignerKey(account, scriptType)
+	if err != nil {
+		return Cosigner{}, err
+	}
+
+	xpub, err := key.Neuter()
+	if err != nil {
+		return Cosigner{}, fmt.Errorf("error deriving cosigner xpub: %w", err)
+	}
+
+	fingerprint, err := w.Fingerprint()
+	if err != nil {
+		return Cosigner{}, err
+	}
+
+	return Cosigner{
+		ScriptType:  scriptType,
+		Fingerprint: hex.EncodeToString(fingerprint),
+		Path:        fmt.Sprintf("m/48'/%d'/%d'/%d'", w.Params.HDCoinType, account, scriptType),
+		Xpub:        xpub.String(),
+	}, nil
+}
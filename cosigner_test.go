@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestCosigner checks the BIP-48 cosigner keys of the test mnemonic against
+// those hardware wallets export for it. It uses testnet, which every build
+// includes.
+func TestCosigner(t *testing.T) {
+	wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for scriptType, expected := range map[uint32]string{
+		BIP48P2SHP2WSH: "[73c5da0a/48'/1'/0'/1']tpubDFH9dgzveyD8yHQb8VrpG8FYAuwcLMHMje2CCcbBo1FpaGzYVtJeYYxcYgRqSTta5utUFts8nPPHs9C2bqoxrey5jia6Dwf9mpwrPq7YvcJ",
+		BIP48P2WSH:     "[73c5da0a/48'/1'/0'/2']tpubDFH9dgzveyD8zTbPUFuLrGmCydNvxehyNdUXKJAQN8x4aZ4j6UZqGfnqFrD4NqyaTVGKbvEW54tsvPTK2UoSbCC1PJY8iCNiwTL3RWZEheQ",
+	} {
+		cosigner, err := wallet.Cosigner(0, scriptType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cosigner.KeyOrigin() != expected {
+			t.Fatalf("%s cosigner %s, expected %s", BIP48ScriptTypeName(scriptType), cosigner.KeyOrigin(), expected)
+		}
+	}
+
+	if _, err := wallet.CosignerKey(0, 3); err == nil {
+		t.Fatalf("BIP-48 script type 3 accepted")
+	}
+}
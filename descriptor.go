@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+func descriptorPolymod(c uint64, val uint64) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ val
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+
+	return c
+}
+
+// DescriptorChecksum computes the BIP-380 checksum of an output descriptor
+func DescriptorChecksum(desc string) (string, error) {
+	c := uint64(1)
+	cls := uint64(0)
+	clsCount := 0
+
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return "", fmt.Errorf("invalid descriptor character %q", ch)
+		}
+
+		c = descriptorPolymod(c, uint64(pos&31))
+		cls = cls*3 + uint64(pos>>5)
+		clsCount++
+		if clsCount == 3 {
+			c = descriptorPolymod(c, cls)
+			cls = 0
+			clsCount = 0
+		}
+	}
+	if clsCount > 0 {
+		c = descriptorPolymod(c, cls)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-i)))&31]
+	}
+
+	return string(checksum), nil
+}
+
+// WalletDescriptor is the descriptor of one chain of an address type
+type WalletDescriptor struct {
+	Type       AddressType
+	Change     uint32
+	Descriptor string
+}
+
+// Descriptors returns the receive and change descriptors of each type
+func (w *Wallet) Descriptors(types []AddressType) ([]WalletDescriptor, error) {
+	var descriptors []WalletDescriptor
+	for _, t := range types {
+		for change := uint32(0); change <= 1; change++ {
+			desc, err := w.Descriptor(t, change)
+			if err != nil {
+				return nil, fmt.Errorf("error building %s descriptor: %w", t, err)
+			}
+
+			descriptors = append(descriptors, WalletDescriptor{Type: t, Change: change, Descriptor: desc})
+		}
+	}
+
+	return descriptors, nil
+}
+
+// ChainName returns the label of the descriptor's chain
+func (d WalletDescriptor) ChainName() string {
+	return ChainName(d.Change)
+}
+
+// Descriptor returns the ranged output descriptor of the address type for
+// the receive (change 0) or change (change 1) chain, with its checksum
+func (w *Wallet) Descriptor(t AddressType, change uint32) (string, error) {
+	fingerprint, err := w.Fingerprint()
+	if err != nil {
+		return "", err
+	}
+
+	account, err := w.AccountKey(t.Purpose())
+	if err != nil {
+		return "", err
+	}
+
+	xpub, err := account.Neuter()
+	if err != nil {
+		return "", fmt.Errorf("error deriving account xpub: %w", err)
+	}
+
+	key := fmt.Sprintf("[%s/%d'/%d'/0']%s/%d/*", hex.EncodeToString(fingerprint), t.Purpose(), w.Params.HDCoinType, xpub, change)
+
+	var desc string
+	switch t {
+	case AddressP2PKH:
+		desc = fmt.Sprintf("pkh(%s)", key)
+	case AddressP2WPKHInP2SH:
+		desc = fmt.Sprintf("sh(wpkh(%s))", key)
+	case AddressP2WPKH:
+		desc = fmt.Sprintf("wpkh(%s)", key)
+	case AddressTaproot:
+		desc = fmt.Sprintf("tr(%s)", key)
+	default:
+		return "", fmt.Errorf("unknown address type %d", int(t))
+	}
+
+	checksum, err := DescriptorChecksum(desc)
+	if err != nil {
+		return "", err
+	}
+
+	return desc + "#" + checksum, nil
+}
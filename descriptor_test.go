@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+// TestDescriptorChecksum checks the BIP-380 checksum against the example of
+// the Bitcoin Core descriptor documentation
+func TestDescriptorChecksum(t *testing.T) {
+	checksum, err := DescriptorChecksum("pkh([d34db33f/44'/0'/0']xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL/1/*)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if checksum != "ml40v0wf" {
+		t.Errorf("checksum %s, expected ml40v0wf", checksum)
+	}
+}
+
+// TestDescriptors checks the receive and change descriptors of every type
+// and their checksums. The account xpubs are the BIP-44, BIP-49, BIP-84 and
+// BIP-86 test vector keys of bip86Mnemonic.
+func TestDescriptors(t *testing.T) {
+	expected := []struct {
+		typ        AddressType
+		change     uint32
+		descriptor string
+	}{
+		{AddressP2PKH, 0, "pkh([73c5da0a/44'/0'/0']xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj/0/*)#8w4z8fed"},
+		{AddressP2PKH, 1, "pkh([73c5da0a/44'/0'/0']xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj/1/*)#k6sr6uf4"},
+		{AddressP2WPKHInP2SH, 0, "sh(wpkh([73c5da0a/49'/0'/0']xpub6C6nQwHaWbSrzs5tZ1q7m5R9cPK9eYpNMFesiXsYrgc1P8bvLLAet9JfHjYXKjToD8cBRswJXXbbFpXgwsswVPAZzKMa1jUp2kVkGVUaJa7/0/*))#gvfpdstz"},
+		{AddressP2WPKHInP2SH, 1, "sh(wpkh([73c5da0a/49'/0'/0']xpub6C6nQwHaWbSrzs5tZ1q7m5R9cPK9eYpNMFesiXsYrgc1P8bvLLAet9JfHjYXKjToD8cBRswJXXbbFpXgwsswVPAZzKMa1jUp2kVkGVUaJa7/1/*))#ad8h407a"},
+		{AddressP2WPKH, 0, "wpkh([73c5da0a/84'/0'/0']xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V/0/*)#wc3n3van"},
+		{AddressP2WPKH, 1, "wpkh([73c5da0a/84'/0'/0']xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4RHwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V/1/*)#lv5jvedt"},
+		{AddressTaproot, 0, "tr([73c5da0a/86'/0'/0']xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ/0/*)#rg247h69"},
+		{AddressTaproot, 1, "tr([73c5da0a/86'/0'/0']xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ/1/*)#ju05rz2a"},
+	}
+
+	descriptors, err := testWallet(t).Descriptors(AddressTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(descriptors) != len(expected) {
+		t.Fatalf("%d descriptors, expected %d", len(descriptors), len(expected))
+	}
+
+	for i, v := range expected {
+		d := descriptors[i]
+		if d.Type != v.typ || d.Change != v.change || d.Descriptor != v.descriptor {
+			t.Errorf("%s descriptor of chain %d is %s, expected %s", v.typ.Name(), v.change, d.Descriptor, v.descriptor)
+		}
+	}
+}
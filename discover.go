@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// AccountActivity is the history found in one account by DiscoverAccounts
+type AccountActivity struct {
+	Account uint32
+	Path    string
+
+	// Used lists the receive indices with history among the scanned ones
+	Used []uint32
+
+	// TxCount sums the transactions of the used addresses
+	TxCount int
+}
+
+// DiscoverAccounts runs BIP-44 account discovery for the purpose: starting at
+// account 0 it scans the first gapLimit receive addresses of each account and
+// stops at the first account without any history, which is not included.
+// At most maxAccounts accounts are scanned.
+func (w *Wallet) DiscoverAccounts(explorer Explorer, bip uint32, gapLimit int, maxAccounts int) ([]AccountActivity, error) {
+	t, err := AddressTypeForPurpose(bip)
+	if err != nil {
+		return nil, err
+	}
+
+	if gapLimit < 1 {
+		return nil, fmt.Errorf("invalid gap limit %d: must be at least 1", gapLimit)
+	}
+
+	var active []AccountActivity
+	for account := uint32(0); int(account) < maxAccounts; account++ {
+		accountKey, err := w.AccountKeyAt(bip, account)
+		if err != nil {
+			return nil, err
+		}
+
+		// Derive the account's addresses through a watch-only wallet, whose
+		// chain cache stands in for account 0 of the purpose
+		accountWallet, err := NewWatchOnlyWallet(accountKey, t, w.Params)
+		if err != nil {
+			return nil, err
+		}
+
+		activity := AccountActivity{
+			Account: account,
+			Path:    fmt.Sprintf("m/%d'/%d'/%d'", bip, w.Params.HDCoinType, account),
+		}
+
+		for index := uint32(0); int(index) < gapLimit; index++ {
+			address, err := accountWallet.DeriveAddress(t, 0, index)
+			if err != nil {
+				return nil, fmt.Errorf("account %d: %w", account, err)
+			}
+
+			count, err := explorer.TxCount(address.EncodeAddress())
+			if err != nil {
+				return nil, fmt.Errorf("account %d: %w", account, err)
+			}
+
+			if count > 0 {
+				activity.Used = append(activity.Used, index)
+				activity.TxCount += count
+			}
+		}
+
+		slog.Debug("scanned account", "path", activity.Path, "used", len(activity.Used))
+
+		if len(activity.Used) == 0 {
+			return active, nil
+		}
+
+		active = append(active, activity)
+	}
+
+	slog.Warn("all scanned accounts have history, raise the account limit to continue", "accounts", maxAccounts)
+
+	return active, nil
+}
+
+// maxUnusedScan bounds the receive indices NextUnusedAddresses looks through
+const maxUnusedScan = 100000
+
+// NextUnusedAddresses returns the first count receive addresses of the purpose
+// that have no history according to the explorer, skipping the used ones, in
+// index order. An explorer error fails the whole call rather than risking
+// handing out an address that was used before.
+func (w *Wallet) NextUnusedAddresses(explorer Explorer, bip uint32, count uint32) ([]indexedAddress, error) {
+	t, err := AddressTypeForPurpose(bip)
+	if err != nil {
+		return nil, err
+	}
+
+	if count < 1 {
+		return nil, fmt.Errorf("invalid count %d: must be at least 1", count)
+	}
+
+	var unused []indexedAddress
+	for index := uint32(0); uint32(len(unused)) < count; index++ {
+		if index >= maxUnusedScan {
+			return nil, fmt.Errorf("found only %d unused addresses in the first %d indices", len(unused), maxUnusedScan)
+		}
+
+		address, err := w.DeriveAddress(t, 0, index)
+		if err != nil {
+			return nil, err
+		}
+
+		txCount, err := explorer.TxCount(address.EncodeAddress())
+		if err != nil {
+			return nil, fmt.Errorf("error checking %s: %w", w.DerivationPath(bip, 0, index), err)
+		}
+
+		if txCount > 0 {
+			slog.Debug("skipping used address", "path", w.DerivationPath(bip, 0, index), "transactions", txCount)
+			continue
+		}
+
+		unused = append(unused, indexedAddress{Address: address.EncodeAddress(), Index: index})
+	}
+
+	return unused, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// mapExplorer is an offline Explorer answering from a map of tx counts
+type mapExplorer map[string]int
+
+func (m mapExplorer) TxCount(address string) (int, error) {
+	return m[address], nil
+}
+
+// TestDiscover runs account discovery against an offline explorer with
+// history in accounts 0 and 1 and checks that it stops at account 2
+func TestDiscover(t *testing.T) {
+	wallet := testWallet(t)
+
+	accountKey, err := wallet.AccountKeyAt(84, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account1, err := NewWatchOnlyWallet(accountKey, AddressP2WPKH, wallet.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	used, err := account1.DeriveAddress(AddressP2WPKH, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	explorer := mapExplorer{bip84Address: 2, used.EncodeAddress(): 1}
+
+	active, err := wallet.DiscoverAccounts(explorer, 84, 20, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(active) != 2 || active[1].Path != "m/84'/0'/1'" || len(active[1].Used) != 1 || active[1].Used[0] != 3 {
+		t.Fatalf("account discovery found %+v, expected accounts 0 and 1 with index 3 used in account 1", active)
+	}
+}
+
+// failingExplorer is an offline Explorer that is always unavailable
+type failingExplorer struct{}
+
+func (failingExplorer) TxCount(address string) (int, error) {
+	return 0, fmt.Errorf("explorer unavailable")
+}
+
+// TestNextUnused skips receive indices 0 and 2, which have history, and
+// checks that an unavailable explorer fails instead of returning addresses
+func TestNextUnused(t *testing.T) {
+	wallet := testWallet(t)
+
+	used, err := wallet.DeriveAddress(AddressP2WPKH, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unused, err := wallet.NextUnusedAddresses(mapExplorer{bip84Address: 1, used.EncodeAddress(): 3}, 84, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var indices []uint32
+	for _, address := range unused {
+		indices = append(indices, address.Index)
+	}
+
+	if !slices.Equal(indices, []uint32{1, 3, 4}) {
+		t.Fatalf("next unused indices %v, expected [1 3 4]", indices)
+	}
+
+	expected, err := wallet.DeriveAddress(AddressP2WPKH, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if unused[0].Address != expected.EncodeAddress() {
+		t.Fatalf("next unused address %s, expected %s", unused[0].Address, expected.EncodeAddress())
+	}
+
+	if unused, err := wallet.NextUnusedAddresses(failingExplorer{}, 84, 1); err == nil {
+		t.Fatalf("unavailable explorer returned %v", unused)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ReadEntropyFile reads the entropy of a bitSize wallet from the start of a
+// raw binary file, e.g. the output of an air-gapped TRNG. Bytes beyond the
+// required length are ignored unless exact is set, which rejects them. The
+// caller should zero the returned buffer once the wallet is built.
+func ReadEntropyFile(path string, bitSize int, exact bool) ([]byte, error) {
+	if bitSize%32 != 0 || bitSize < 128 || bitSize > 256 {
+		return nil, bip39.ErrEntropyLengthInvalid
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening entropy file: %w", err)
+	}
+	defer file.Close()
+
+	entropy := make([]byte, bitSize/8)
+	if n, err := io.ReadFull(file, entropy); err != nil {
+		clear(entropy)
+
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("entropy file has %d bytes, %d bit entropy needs %d", n, bitSize, len(entropy))
+		}
+
+		return nil, fmt.Errorf("error reading entropy file: %w", err)
+	}
+
+	if exact {
+		var extra [1]byte
+		if n, _ := file.Read(extra[:]); n > 0 {
+			clear(entropy)
+			clear(extra[:])
+
+			return nil, fmt.Errorf("entropy file is longer than the %d bytes of %d bit entropy", len(entropy), bitSize)
+		}
+	}
+
+	return entropy, nil
+}
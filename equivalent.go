@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// mnemonicLanguages are the BIP-39 wordlists MnemonicsEquivalent recognizes
+var mnemonicLanguages = []struct {
+	name  string
+	words []string
+}{
+	{"english", wordlists.English},
+	{"spanish", wordlists.Spanish},
+	{"french", wordlists.French},
+	{"italian", wordlists.Italian},
+	{"czech", wordlists.Czech},
+	{"japanese", wordlists.Japanese},
+	{"korean", wordlists.Korean},
+	{"chinese_simplified", wordlists.ChineseSimplified},
+	{"chinese_traditional", wordlists.ChineseTraditional},
+}
+
+// mnemonicLanguage returns the wordlist holding every word of the normalized
+// mnemonic and checks its checksum there. The go-bip39 wordlist is global, so
+// the checksum is verified here instead of switching it.
+func mnemonicLanguage(mnemonic string) (string, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return "", fmt.Errorf("invalid mnemonic: %d words", len(words))
+	}
+
+	for _, language := range mnemonicLanguages {
+		indices := make(map[string]int64, len(language.words))
+		for i, word := range language.words {
+			indices[norm.NFKD.String(word)] = int64(i)
+		}
+
+		// Chinese wordlists share most of their characters, so keep looking
+		// when the checksum fails in one of them
+		bits := new(big.Int)
+		found := true
+		for _, word := range words {
+			index, ok := indices[word]
+			if !ok {
+				found = false
+				break
+			}
+			bits.Lsh(bits, 11).Or(bits, big.NewInt(index))
+		}
+		if !found {
+			continue
+		}
+
+		checksumBits := uint(len(words) / 3)
+		checksum := new(big.Int).And(bits, big.NewInt(int64(1)<<checksumBits-1))
+		entropy := make([]byte, len(words)*4/3)
+		new(big.Int).Rsh(bits, checksumBits).FillBytes(entropy)
+
+		hash := sha256.Sum256(entropy)
+		if uint64(hash[0]>>(8-checksumBits)) == checksum.Uint64() {
+			return language.name, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid mnemonic: not a valid mnemonic of any BIP-39 wordlist")
+}
+
+// MnemonicsEquivalent reports whether two mnemonics derive the same seed, and
+// so the same keys and addresses, under the passphrase. Both are normalized
+// first, so spacing, case and Unicode composition do not matter, and each may
+// use any BIP-39 wordlist. Note that the seed is derived from the words, not
+// the entropy: the same entropy written in two languages derives different
+// wallets and is reported as not equivalent.
+func MnemonicsEquivalent(a, b string, passphrase string) (bool, error) {
+	var seeds [2][]byte
+	for i, mnemonic := range []string{a, b} {
+		normalized := normalizeMnemonic(mnemonic)
+		if _, err := mnemonicLanguage(normalized); err != nil {
+			return false, fmt.Errorf("mnemonic %d: %w", i+1, err)
+		}
+
+		seeds[i] = newSeed(normalized, norm.NFKD.String(passphrase))
+	}
+
+	return SecureCompare(seeds[0], seeds[1]), nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TestMnemonicsEquivalent compares the all zero entropy mnemonic in English
+// and Spanish, which encode the same entropy but derive different seeds, and
+// respelled forms of each that derive the same one
+func TestMnemonicsEquivalent(t *testing.T) {
+	spanish := strings.Repeat(wordlists.Spanish[0]+" ", 11) + wordlists.Spanish[3]
+
+	for _, v := range []struct {
+		a, b       string
+		equivalent bool
+	}{
+		{bip86Mnemonic, "  " + strings.ToUpper(bip86Mnemonic) + "\n", true},
+		{spanish, norm.NFD.String(spanish), true},
+		{bip86Mnemonic, spanish, false},
+	} {
+		equivalent, err := MnemonicsEquivalent(v.a, v.b, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if equivalent != v.equivalent {
+			t.Fatalf("mnemonics %q and %q equivalent %t, expected %t", v.a, v.b, equivalent, v.equivalent)
+		}
+	}
+
+	if _, err := MnemonicsEquivalent(bip86Mnemonic, strings.Repeat(wordlists.Spanish[0]+" ", 12), ""); err == nil {
+		t.Fatalf("mnemonic with a bad checksum accepted")
+	}
+}
+
+// TestMnemonicsEquivalentTampered checks a mnemonic whose entropy differs
+// only in the last bit derives a different seed
+func TestMnemonicsEquivalentTampered(t *testing.T) {
+	entropy := make([]byte, 16)
+	entropy[len(entropy)-1] = 1
+
+	flipped, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if equivalent, err := MnemonicsEquivalent(bip86Mnemonic, flipped, ""); err != nil || equivalent {
+		t.Errorf("mnemonic %q equivalent %t (%v), expected a different seed", flipped, equivalent, err)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// Explorer looks up the on-chain history of addresses. It is an interface so
+// that discovery can run against a fake in tests and offline checks.
+type Explorer interface {
+	// TxCount returns the count of confirmed and unconfirmed transactions
+	// involving the address
+	TxCount(address string) (int, error)
+}
+
+// ExplorerUTXO is an unspent output of an address as reported by an explorer
+type ExplorerUTXO struct {
+	Txid  string `json:"txid"`
+	Vout  uint32 `json:"vout"`
+	Value int64  `json:"value"`
+}
+
+// UTXOExplorer also lists unspent outputs and fetches transactions, as needed
+// to spend from the addresses
+type UTXOExplorer interface {
+	Explorer
+
+	// UTXOs returns the confirmed and unconfirmed unspent outputs of the address
+	UTXOs(address string) ([]ExplorerUTXO, error)
+
+	// RawTx returns the transaction with the txid
+	RawTx(txid string) (*wire.MsgTx, error)
+}
+
+// esploraURLs are the public Esplora APIs of the networks that have one
+var esploraURLs = map[string]string{
+	chaincfg.MainNetParams.Name:  "https://blockstream.info/api",
+	chaincfg.TestNet3Params.Name: "https://blockstream.info/testnet/api",
+	chaincfg.SigNetParams.Name:   "https://mempool.space/signet/api",
+}
+
+// EsploraURL returns the public Esplora API of the network
+func EsploraURL(params *chaincfg.Params) (string, error) {
+	base, ok := esploraURLs[params.Name]
+	if !ok {
+		return "", fmt.Errorf("no public explorer for network %s, set -explorer", params.Name)
+	}
+
+	return base, nil
+}
+
+// EsploraExplorer queries an Esplora HTTP API, e.g. blockstream.info,
+// mempool.space or a self-hosted electrs
+type EsploraExplorer struct {
+	BaseURL string
+	Client  *http.Client
+
+	// Limiter, when set, paces every request including retries
+	Limiter Limiter
+
+	// Attempts bounds the requests made for one lookup while the API
+	// answers 429 Too Many Requests
+	Attempts int
+
+	// Backoff is the delay before the first retry of a 429, doubled for
+	// each next one unless the response has a Retry-After in seconds
+	Backoff time.Duration
+
+	// Sleep waits out the backoff, replaced by the tests
+	Sleep func(time.Duration)
+}
+
+// NewEsploraExplorer returns an Esplora client for the API at baseURL,
+// making up to 4 attempts starting with a 1 second backoff
+func NewEsploraExplorer(baseURL string) *EsploraExplorer {
+	return &EsploraExplorer{
+		BaseURL:  strings.TrimSuffix(baseURL, "/"),
+		Client:   &http.Client{Timeout: 30 * time.Second},
+		Attempts: 4,
+		Backoff:  time.Second,
+		Sleep:    time.Sleep,
+	}
+}
+
+// NewRateLimitedExplorer returns an Esplora client making at most rate
+// requests per second, rate 0 leaves it unlimited
+func NewRateLimitedExplorer(baseURL string, rate float64) (*EsploraExplorer, error) {
+	explorer := NewEsploraExplorer(baseURL)
+	if rate == 0 {
+		return explorer, nil
+	}
+
+	limiter, err := NewTokenBucket(rate)
+	if err != nil {
+		return nil, err
+	}
+	explorer.Limiter = limiter
+
+	return explorer, nil
+}
+
+// get requests the URL through the limiter, retrying 429 responses. The last
+// response is returned whatever its status, for the caller to report.
+func (e *EsploraExplorer) get(rawURL string) (*http.Response, error) {
+	delay := e.Backoff
+	for attempt := 1; ; attempt++ {
+		if e.Limiter != nil {
+			e.Limiter.Wait()
+		}
+
+		resp, err := e.Client.Get(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("error querying explorer: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= e.Attempts {
+			return resp, nil
+		}
+
+		wait := delay
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait = time.Duration(seconds) * time.Second
+		}
+		resp.Body.Close()
+
+		slog.Warn("explorer rate limited the request, retrying", "attempt", attempt, "attempts", e.Attempts, "delay", wait)
+		e.Sleep(wait)
+		delay *= 2
+	}
+}
+
+// esploraStats are the transaction statistics of an Esplora address response
+type esploraStats struct {
+	TxCount int `json:"tx_count"`
+}
+
+func (e *EsploraExplorer) TxCount(address string) (int, error) {
+	resp, err := e.get(e.BaseURL + "/address/" + url.PathEscape(address))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("explorer returned %s for address %s", resp.Status, address)
+	}
+
+	var info struct {
+		ChainStats   esploraStats `json:"chain_stats"`
+		MempoolStats esploraStats `json:"mempool_stats"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return 0, fmt.Errorf("error decoding explorer response: %w", err)
+	}
+
+	return info.ChainStats.TxCount + info.MempoolStats.TxCount, nil
+}
+
+func (e *EsploraExplorer) UTXOs(address string) ([]ExplorerUTXO, error) {
+	resp, err := e.get(e.BaseURL + "/address/" + url.PathEscape(address) + "/utxo")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("explorer returned %s for the UTXOs of address %s", resp.Status, address)
+	}
+
+	var utxos []ExplorerUTXO
+	if err := json.NewDecoder(resp.Body).Decode(&utxos); err != nil {
+		return nil, fmt.Errorf("error decoding explorer response: %w", err)
+	}
+
+	return utxos, nil
+}
+
+func (e *EsploraExplorer) RawTx(txid string) (*wire.MsgTx, error) {
+	resp, err := e.get(e.BaseURL + "/tx/" + url.PathEscape(txid) + "/hex")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("explorer returned %s for transaction %s", resp.Status, txid)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading explorer response: %w", err)
+	}
+
+	raw, err := hex.DecodeString(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding transaction %s: %w", txid, err)
+	}
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	if err := tx.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, fmt.Errorf("error decoding transaction %s: %w", txid, err)
+	}
+
+	return tx, nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MACFileName returns the sidecar file holding the HMAC of path, the output
+// file itself is left unchanged so it still parses as plain CSV or JSON
+func MACFileName(path string) string {
+	return path + ".hmac"
+}
+
+// ReadMACKey reads the HMAC key from a file. Like a passphrase file a single
+// trailing newline is removed, an empty key is rejected.
+func ReadMACKey(path string) ([]byte, error) {
+	key, err := ReadPassphraseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading HMAC key: %w", err)
+	}
+
+	if len(key) == 0 {
+		return nil, fmt.Errorf("HMAC key file %s is empty", path)
+	}
+
+	return []byte(key), nil
+}
+
+// ComputeFileMAC returns the HMAC-SHA256 of the file contents under key
+func ComputeFileMAC(path string, key []byte) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	mac := hmac.New(sha256.New, key)
+	if _, err := io.Copy(mac, file); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	return mac.Sum(nil), nil
+}
+
+// WriteFileMAC writes the HMAC of path to its sidecar file, formatted like
+// sha256sum as the hex MAC followed by the file name
+func WriteFileMAC(path string, key []byte) error {
+	sum, err := ComputeFileMAC(path, key)
+	if err != nil {
+		return err
+	}
+
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum), filepath.Base(path))
+	if err := os.WriteFile(MACFileName(path), []byte(line), 0o666); err != nil {
+		return fmt.Errorf("error writing HMAC file: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyFileMAC checks path against the HMAC in its sidecar file. A modified
+// or truncated file and a wrong key fail alike, as the HMAC cannot tell them
+// apart.
+func VerifyFileMAC(path string, key []byte) error {
+	data, err := os.ReadFile(MACFileName(path))
+	if err != nil {
+		return fmt.Errorf("error reading HMAC file: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return fmt.Errorf("HMAC file %s is empty", MACFileName(path))
+	}
+
+	expected, err := hex.DecodeString(fields[0])
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("HMAC file %s does not hold a hex HMAC-SHA256", MACFileName(path))
+	}
+
+	sum, err := ComputeFileMAC(path, key)
+	if err != nil {
+		return err
+	}
+
+	if !SecureCompare(sum, expected) {
+		return fmt.Errorf("HMAC mismatch: %s was modified or truncated, or the key is wrong", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFileMAC signs a file in a temporary directory and checks that the
+// HMAC verifies, and fails once the file is modified, truncated or checked
+// under another key
+func TestFileMAC(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "wallets.csv")
+	content := "Mnemonic,P2WPKH\n" + bip86Mnemonic + ",bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu\n"
+	key := []byte("test key")
+
+	if err := os.WriteFile(path, []byte(content), 0o666); err != nil {
+		t.Fatalf("error writing test file: %v", err)
+	}
+
+	if err := WriteFileMAC(path, key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyFileMAC(path, key); err != nil {
+		t.Fatalf("HMAC of an unchanged file rejected: %v", err)
+	}
+
+	if err := VerifyFileMAC(path, []byte("other key")); err == nil {
+		t.Fatalf("HMAC verified under the wrong key")
+	}
+
+	tampered := map[string]string{
+		"modified":  strings.Replace(content, "bc1qcr8", "bc1qcr9", 1),
+		"truncated": content[:len(content)/2],
+	}
+
+	for name, data := range tampered {
+		if err := os.WriteFile(path, []byte(data), 0o666); err != nil {
+			t.Fatalf("error writing test file: %v", err)
+		}
+
+		if err := VerifyFileMAC(path, key); err == nil {
+			t.Fatalf("HMAC verified a %s file", name)
+		}
+	}
+}
+
+// TestFileMACTampered checks an unchanged file fails against a sidecar HMAC
+// differing only in its last byte
+func TestFileMACTampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallets.csv")
+	key := []byte("test key")
+
+	if err := os.WriteFile(path, []byte(bip86Mnemonic+"\n"), 0o666); err != nil {
+		t.Fatalf("error writing test file: %v", err)
+	}
+
+	sum, err := ComputeFileMAC(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum[len(sum)-1] ^= 1
+
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum), filepath.Base(path))
+	if err := os.WriteFile(MACFileName(path), []byte(line), 0o666); err != nil {
+		t.Fatalf("error writing HMAC file: %v", err)
+	}
+
+	if err := VerifyFileMAC(path, key); err == nil {
+		t.Fatalf("tampered HMAC verified")
+	}
+}
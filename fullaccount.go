@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// AccountRow holds the receive and change addresses of every type at one index
+type AccountRow struct {
+	Index   uint32
+	Receive AddressSet
+	Change  AddressSet
+}
+
+// DeriveFullAccount derives both chains of every type at each index
+func (w *Wallet) DeriveFullAccount(indices []uint32) ([]AccountRow, error) {
+	rows := make([]AccountRow, 0, len(indices))
+	for _, index := range indices {
+		receive, err := w.DeriveAll(0, index)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving receive addresses at index %d: %w", index, err)
+		}
+
+		change, err := w.DeriveAll(1, index)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving change addresses at index %d: %w", index, err)
+		}
+
+		rows = append(rows, AccountRow{Index: index, Receive: receive, Change: change})
+	}
+
+	return rows, nil
+}
+
+// WriteFullAccount writes the account snapshot CSV: one row per wallet and
+// index with the receive and change address of every type and their paths
+func WriteFullAccount(fileName string, wallets []Generated) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	header := []string{"#", "Index"}
+	for _, t := range AddressTypes {
+		for _, change := range []uint32{0, 1} {
+			header = append(header, fmt.Sprintf("%s %s Address", t, ChainName(change)), fmt.Sprintf("%s %s Path", t, ChainName(change)))
+		}
+	}
+
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for i, wallet := range wallets {
+		for _, row := range wallet.FullAccount {
+			record := []string{strconv.Itoa(i + 1), strconv.FormatUint(uint64(row.Index), 10)}
+			for _, t := range AddressTypes {
+				for change, set := range []AddressSet{row.Receive, row.Change} {
+					path := fmt.Sprintf("m/%d'/%d'/0'/%d/%d", wallet.Purposes[t], wallet.CoinType, change, row.Index)
+					record = append(record, set.Address(t).EncodeAddress(), path)
+				}
+			}
+
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("error writing record: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
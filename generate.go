@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// GenerateOptions configures Generate
+type GenerateOptions struct {
+	Count      int
+	BitSize    int
+	Passphrase string
+	Params     *chaincfg.Params
+
+	// Rand is the entropy source, crypto/rand when nil
+	Rand io.Reader
+}
+
+// Generate creates opts.Count wallets one at a time, handing each to fn as
+// soon as it is generated so only one wallet is held in memory. The first
+// error of fn aborts the batch and is returned wrapped with the wallet number.
+func Generate(opts GenerateOptions, fn func(index int, w *Wallet) error) error {
+	if opts.Count < 1 {
+		return fmt.Errorf("invalid count %d: must be at least 1", opts.Count)
+	}
+
+	if opts.Params == nil {
+		return fmt.Errorf("no network parameters")
+	}
+
+	for i := 0; i < opts.Count; i++ {
+		var wallet *Wallet
+		var err error
+		if opts.Rand != nil {
+			wallet, err = NewWalletWithRand(opts.BitSize, opts.Rand, opts.Passphrase, opts.Params)
+		} else {
+			wallet, err = NewWallet(opts.BitSize, opts.Passphrase, opts.Params)
+		}
+		if err != nil {
+			return fmt.Errorf("error generating wallet %d: %w", i+1, err)
+		}
+
+		if err := fn(i, wallet); err != nil {
+			return fmt.Errorf("wallet %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestGenerate checks Generate hands out every wallet in order and stops at
+// the first callback error
+func TestGenerate(t *testing.T) {
+	opts := GenerateOptions{Count: 3, BitSize: 128, Params: &chaincfg.MainNetParams, Rand: bytes.NewReader(make([]byte, 3*16))}
+
+	var mnemonics []string
+	err := Generate(opts, func(index int, w *Wallet) error {
+		if index != len(mnemonics) {
+			t.Errorf("index %d, expected %d", index, len(mnemonics))
+		}
+		mnemonics = append(mnemonics, w.Mnemonic)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// All zero entropy is the abandon ... about mnemonic
+	if len(mnemonics) != 3 || mnemonics[2] != bip86Mnemonic {
+		t.Fatalf("Generate produced %d wallets, expected 3 of %q", len(mnemonics), bip86Mnemonic)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	opts.Rand = nil
+	err = Generate(opts, func(index int, w *Wallet) error {
+		calls++
+		if index == 1 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 2 {
+		t.Fatalf("Generate made %d calls and returned %v, expected to stop after 2", calls, err)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// identiconColors and identiconAnimals are indexed by the high and low nibble
+// of each fingerprint byte. The lists are part of the output format and must
+// never be reordered, otherwise devices would show different identicons.
+var (
+	identiconColors = [16]string{
+		"red", "orange", "amber", "yellow", "lime", "green", "teal", "cyan",
+		"blue", "navy", "indigo", "violet", "pink", "brown", "gray", "black",
+	}
+	identiconAnimals = [16]string{
+		"ant", "bear", "cat", "deer", "eagle", "fox", "goat", "hawk",
+		"ibis", "koala", "lion", "mole", "newt", "owl", "panda", "wolf",
+	}
+)
+
+// Identicon maps a fingerprint to a short phrase of color and animal pairs,
+// one per byte, so two devices can be visually compared for the same seed.
+// The words are deliberately not BIP-39 words to avoid mistaking them for a
+// mnemonic.
+func Identicon(fingerprint []byte) string {
+	words := make([]string, 0, len(fingerprint))
+	for _, b := range fingerprint {
+		words = append(words, fmt.Sprintf("%s %s", identiconColors[b>>4], identiconAnimals[b&0x0f]))
+	}
+
+	return strings.Join(words, ", ")
+}
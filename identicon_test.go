@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+// bip86Identicon is the identicon of the mnemonic's fingerprint 73c5da0a
+const bip86Identicon = "cyan deer, pink fox, brown lion, red lion"
+
+// TestIdenticon checks that the identicon mapping stays stable
+func TestIdenticon(t *testing.T) {
+	wallet := testWallet(t)
+
+	fingerprint, err := wallet.Fingerprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identicon := Identicon(fingerprint); identicon != bip86Identicon {
+		t.Fatalf("identicon %q, expected %q", identicon, bip86Identicon)
+	}
+}
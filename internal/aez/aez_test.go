@@ -0,0 +1,133 @@
+// aez_test.go - AEZ tests.
+//
+// To the extent possible under law, Yawning Angel has waived all copyright
+// and related or neighboring rights to aez, using the Creative
+// Commons "CC0" public domain dedication. See LICENSE or
+// <http://creativecommons.org/publicdomain/zero/1.0/> for full details.
+
+package aez
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readJsonTestdata(t *testing.T, name string, destination interface{}) {
+	var file *os.File
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read test vectors in %s", name)
+	}
+
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&destination); err != nil {
+		t.Fatalf("Failed to parse test vectors in %s", name)
+	}
+}
+
+// (a,b)  ==>  Extract(a) = b.
+type ExtractVector struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+func TestExtract(t *testing.T) {
+	var extractVectors []ExtractVector
+
+	readJsonTestdata(t, "extract.json", &extractVectors)
+
+	for i, vec := range extractVectors {
+		var extractedKey [extractedKeySize]byte
+
+		vecA, err := hex.DecodeString(vec.A)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vecB, err := hex.DecodeString(vec.B)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		extract(vecA, &extractedKey)
+		assertEqual(t, i, vecB, extractedKey[:])
+	}
+}
+
+// (K, N, A, taubytes, M, C) ==> Encrypt(K,N,A,taubytes,M) = C
+type EncryptVector struct {
+	K     string   `json:"k"`
+	Nonce string   `json:"nonce"`
+	Data  []string `json:"data"`
+	Tau   int      `json:"tau"`
+	M     string   `json:"m"`
+	C     string   `json:"c"`
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	var encryptVectors []EncryptVector
+
+	// The upstream encrypt.json is left out for its size
+	for _, name := range []string{"encrypt_no_ad.json", "encrypt_33_byte_ad.json", "encrypt_16_byte_key.json"} {
+		readJsonTestdata(t, name, &encryptVectors)
+		assertEncrypt(t, encryptVectors)
+	}
+}
+
+func assertEncrypt(t *testing.T, vectors []EncryptVector) {
+	var e eState
+
+	for i, vec := range vectors {
+		vecK, err := hex.DecodeString(vec.K)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vecNonce, err := hex.DecodeString(vec.Nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vecData [][]byte
+		for _, s := range vec.Data {
+			d, err := hex.DecodeString(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vecData = append(vecData, d)
+		}
+		vecM, err := hex.DecodeString(vec.M)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vecC, err := hex.DecodeString(vec.C)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e.init(vecK)
+		c := Encrypt(vecK, vecNonce, vecData, vec.Tau, vecM, nil)
+		assertEqual(t, i, vecC, c)
+
+		m, ok := Decrypt(vecK, vecNonce, vecData, vec.Tau, vecC, nil)
+		if !ok {
+			t.Fatalf("decrypt failed: [%d]", i)
+		}
+		assertEqual(t, i, vecM, m)
+	}
+}
+
+func assertEqual(t *testing.T, idx int, expected, actual []byte) {
+	if !bytes.Equal(expected, actual) {
+		for i, v := range actual {
+			if expected[i] != v {
+				t.Errorf("[%d] first mismatch at offset: %d (%02x != %02x)", idx, i, expected[i], v)
+				break
+			}
+		}
+		t.Errorf("expected: %s", hex.Dump(expected))
+		t.Errorf("actual: %s", hex.Dump(actual))
+		t.FailNow()
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// Labeler returns the label of the address of type t in set, for the
+// 1-based wallet of the batch of wallets
+type Labeler func(t AddressType, set AddressSet, wallet int, wallets int) string
+
+// labelPlaceholders are the placeholders of -label-template in help order
+var labelPlaceholders = []string{"type", "index", "chain", "account", "path", "network", "wallet"}
+
+// ParseLabelTemplate returns the Labeler substituting the placeholders of the
+// template, e.g. "{network} {type} {chain} #{index}" labels "mainnet p2wpkh
+// receive #0". Unknown placeholders and unbalanced braces are rejected.
+func ParseLabelTemplate(template string, params *chaincfg.Params) (Labeler, error) {
+	// Literal text and placeholder names alternate, starting with text
+	var parts []string
+	rest := template
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			parts = append(parts, rest)
+			break
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("invalid label template %q: unmatched }", template)
+		}
+
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] == '{' {
+			return nil, fmt.Errorf("invalid label template %q: unclosed {", template)
+		}
+
+		name := rest[open+1 : open+1+end]
+		if !slices.Contains(labelPlaceholders, name) {
+			return nil, fmt.Errorf("unknown placeholder {%s} in label template, known placeholders are {%s}", name, strings.Join(labelPlaceholders, "}, {"))
+		}
+
+		parts = append(parts, rest[:open], name)
+		rest = rest[open+end+2:]
+	}
+
+	return func(t AddressType, set AddressSet, wallet int, wallets int) string {
+		values := map[string]string{
+			"type":    t.Name(),
+			"index":   strconv.FormatUint(uint64(set.Index), 10),
+			"chain":   strings.ToLower(ChainName(set.Change)),
+			"account": "0",
+			"path":    fmt.Sprintf("m/%d'/%d'/0'/%d/%d", t.Purpose(), params.HDCoinType, set.Change, set.Index),
+			"network": params.Name,
+			"wallet":  strconv.Itoa(wallet),
+		}
+
+		var label strings.Builder
+		for i, part := range parts {
+			if i%2 == 0 {
+				label.WriteString(part)
+			} else {
+				label.WriteString(values[part])
+			}
+		}
+
+		return label.String()
+	}, nil
+}
+
+// addressLabel returns the auto-generated label of an address. The wallet
+// number is only included when several wallets are labeled together.
+func addressLabel(t AddressType, set AddressSet, wallet int, wallets int) string {
+	label := fmt.Sprintf("%s #%d", t.Name(), set.Index)
+	if set.Change != 0 {
+		label = fmt.Sprintf("%s change #%d", t.Name(), set.Index)
+	}
+	if wallets > 1 {
+		label = fmt.Sprintf("wallet %d %s", wallet, label)
+	}
+
+	return label
+}
+
+// WriteSparrowLabels writes the derived addresses as a Sparrow labels CSV
+// with the type,ref,label columns, labeled by label or addressLabel if nil
+func WriteSparrowLabels(fileName string, wallets []Generated, label Labeler) error {
+	if label == nil {
+		label = addressLabel
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	if err := writer.Write([]string{"type", "ref", "label"}); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for i, wallet := range wallets {
+		for _, set := range wallet.AddressSets() {
+			for _, t := range AddressTypes {
+				row := []string{"addr", set.Address(t).EncodeAddress(), label(t, set, i+1, len(wallets))}
+
+				if err := writer.Write(row); err != nil {
+					return fmt.Errorf("error writing record: %w", err)
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// bip329Label is a single record of the BIP-329 wallet labels format
+type bip329Label struct {
+	Type  string `json:"type"`
+	Ref   string `json:"ref"`
+	Label string `json:"label"`
+}
+
+// WriteBIP329Labels writes the derived addresses as BIP-329 JSON lines, one
+// addr record per address, labeled as in WriteSparrowLabels
+func WriteBIP329Labels(fileName string, wallets []Generated, label Labeler) error {
+	if label == nil {
+		label = addressLabel
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	// json.Encoder terminates every record with a newline
+	encoder := json.NewEncoder(file)
+
+	for i, wallet := range wallets {
+		for _, set := range wallet.AddressSets() {
+			for _, t := range AddressTypes {
+				record := bip329Label{
+					Type:  "addr",
+					Ref:   set.Address(t).EncodeAddress(),
+					Label: label(t, set, i+1, len(wallets)),
+				}
+
+				if err := encoder.Encode(record); err != nil {
+					return fmt.Errorf("error writing record: %w", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestLabelTemplate checks the placeholders of -label-template are
+// substituted and malformed templates are rejected
+func TestLabelTemplate(t *testing.T) {
+	label, err := ParseLabelTemplate("{network}: {type} {chain} #{index} ({path}, account {account}, wallet {wallet})", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "mainnet: p2wpkh change #7 (m/84'/0'/0'/1/7, account 0, wallet 2)"
+	if got := label(AddressP2WPKH, AddressSet{Change: 1, Index: 7}, 2, 3); got != expected {
+		t.Fatalf("label %q, expected %q", got, expected)
+	}
+
+	for _, invalid := range []string{"{label}", "{Type}", "#{index", "index}", "{{index}}", "{}"} {
+		if _, err := ParseLabelTemplate(invalid, &chaincfg.MainNetParams); err == nil {
+			t.Fatalf("label template %q was accepted", invalid)
+		}
+	}
+}
+
+// sparrowLabels is the -sparrow-labels export of the first receive and change
+// addresses of bip86Mnemonic
+const sparrowLabels = `type,ref,label
+addr,1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA,p2pkh #0
+addr,37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf,p2sh-p2wpkh #0
+addr,bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu,p2wpkh #0
+addr,bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr,p2tr #0
+addr,1J3J6EvPrv8q6AC3VCjWV45Uf3nssNMRtH,p2pkh change #0
+addr,34K56kSjgUCUSD8GTtuF7c9Zzwokbs6uZ7,p2sh-p2wpkh change #0
+addr,bc1q8c6fshw2dlwun7ekn9qwf37cu2rn755upcp6el,p2wpkh change #0
+addr,bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7,p2tr change #0
+`
+
+// labelWallet returns a generated wallet holding the first receive and change
+// addresses of bip86Mnemonic
+func labelWallet(t *testing.T) Generated {
+	t.Helper()
+
+	wallet := testWallet(t)
+
+	var sets []AddressSet
+	for change := uint32(0); change <= 1; change++ {
+		set, err := wallet.DeriveAll(change, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sets = append(sets, set)
+	}
+
+	return Generated{Addresses: sets}
+}
+
+// TestSparrowLabels pins the type,ref,label column layout Sparrow imports
+func TestSparrowLabels(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "labels.csv")
+	if err := WriteSparrowLabels(fileName, []Generated{labelWallet(t)}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(written) != sparrowLabels {
+		t.Errorf("Sparrow labels\n%s\nexpected\n%s", written, sparrowLabels)
+	}
+}
+
+// TestBIP329Labels parses the -bip329-labels lines back and checks the type,
+// ref and label of every address, numbered by wallet
+func TestBIP329Labels(t *testing.T) {
+	wallet := labelWallet(t)
+
+	fileName := filepath.Join(t.TempDir(), "labels.jsonl")
+	if err := WriteBIP329Labels(fileName, []Generated{wallet, wallet}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var expected []bip329Label
+	for i := 1; i <= 2; i++ {
+		for _, set := range wallet.AddressSets() {
+			for _, typ := range AddressTypes {
+				expected = append(expected, bip329Label{
+					Type:  "addr",
+					Ref:   set.Address(typ).EncodeAddress(),
+					Label: fmt.Sprintf("wallet %d %s", i, addressLabel(typ, set, i, 1)),
+				})
+			}
+		}
+	}
+
+	var records []bip329Label
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record bip329Label
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d: %v", len(records)+1, err)
+		}
+
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != len(expected) {
+		t.Fatalf("%d lines, expected %d", len(records), len(expected))
+	}
+
+	for i, record := range records {
+		if record != expected[i] {
+			t.Errorf("line %d is %+v, expected %+v", i+1, record, expected[i])
+		}
+	}
+
+	first := bip329Label{Type: "addr", Ref: "1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA", Label: "wallet 1 p2pkh #0"}
+	if records[0] != first {
+		t.Errorf("first line is %+v, expected %+v", records[0], first)
+	}
+}
@@ -1,14 +1,20 @@
 package main
 
 import (
-	"encoding/csv"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
 )
 
 type Generated struct {
@@ -16,112 +22,1544 @@ type Generated struct {
 	P2wpkhP2shAddress btcutil.Address
 	P2wpkhAddress     btcutil.Address
 	TaprootAddress    btcutil.Address
+	LegacyBip32       btcutil.Address
 	Mnemonic          string
+	Secret            string
+	MasterXprv        string
+	MasterXpub        string
+	Identicon         string
+	CoinType          uint32
+	Purposes          map[AddressType]uint32
+	FullAccount       []AccountRow
+	TapTweak          []byte
+	ChildXprvs        map[AddressType]string
+	SetHash           string
+	PassphraseIndex   int
+	Tree              string
+	Change            uint32
+	Addresses         []AddressSet
+	Descriptors       []WalletDescriptor
+	Coldcard          *ColdcardExport
+	CoreWallet        []CoreImportRequest
+}
+
+// AddressSets returns the derived address range, or the first addresses when
+// no range was derived
+func (g Generated) AddressSets() []AddressSet {
+	if len(g.Addresses) > 0 {
+		return g.Addresses
+	}
+
+	return []AddressSet{{
+		Change:            g.Change,
+		P2pkhAddress:      g.P2pkhAddress,
+		P2wpkhP2shAddress: g.P2wpkhP2shAddress,
+		P2wpkhAddress:     g.P2wpkhAddress,
+		TaprootAddress:    g.TaprootAddress,
+		TapTweak:          g.TapTweak,
+		ChildXprvs:        g.ChildXprvs,
+	}}
 }
 
 func main() {
-	var (
-		bits  = flag.Int("bits", 128, "Bit size for entropy")
-		count = flag.Int("count", 1, "Count of wallets to generate")
-		out   = flag.String("out", "", "Output file")
-	)
+	if err := run(os.Args[1:]); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// validateStrength checks the wallet count and returns the entropy bit size
+// of each wallet. words is a comma separated list of word counts cycled across
+// the batch, e.g. 12,24 alternates between 12 and 24 word mnemonics. A single
+// word count must agree with an explicit -bits.
+func validateStrength(count int, bits int, words string, bitsSet bool) ([]int, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("invalid wallet count %d: must be at least 1", count)
+	}
+
+	strengths := []int{bits}
+
+	if len(words) > 0 {
+		strengths = nil
+
+		for _, field := range strings.Split(words, ",") {
+			wordCount, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || wordCount < 12 || wordCount > 24 || wordCount%3 != 0 {
+				return nil, fmt.Errorf("invalid word count %q: must be 12, 15, 18, 21 or 24", field)
+			}
+
+			wordBits := wordCount * 32 / 3
+			if bitsSet && bits != wordBits {
+				return nil, fmt.Errorf("-bits %d does not match -words %d, which requires %d bits", bits, wordCount, wordBits)
+			}
+
+			strengths = append(strengths, wordBits)
+		}
+	}
+
+	for _, bits := range strengths {
+		if bits < 128 || bits > 256 || bits%32 != 0 {
+			return nil, fmt.Errorf("invalid bit size %d: must be a multiple of 32 between 128 and 256", bits)
+		}
+	}
+
+	return strengths, nil
+}
+
+// autoFileName returns the path of the -auto-name output file in dir, named
+// after the network and the time so that successive runs sort in order
+func autoFileName(dir string, network string, format string, now time.Time) (string, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("error opening output directory: %w", err)
+	}
+
+	if !info.IsDir() {
+		return "", fmt.Errorf("-auto-name requires -out to be a directory, %s is not", dir)
+	}
+
+	extension := format
+	if format == "text" || format == "table" {
+		extension = "txt"
+	}
+
+	name := fmt.Sprintf("wallets-%s-%s.%s", network, now.Format("20060102T150405"), extension)
+
+	return filepath.Join(dir, name), nil
+}
+
+// generateCommand holds the flags of the generate and restore commands and
+// the values validate resolves from them
+type generateCommand struct {
+	name string
+
+	// set holds the flags given on the command line or by the environment
+	set map[string]bool
+
+	bits     int
+	words    string
+	count    int
+	out      string
+	autoName bool
+	network  string
+	hrp      string
+	paper    string
+	qr       bool
+	format   string
+	full     bool
+
+	countFrom  int
+	continuous bool
+
+	redact    bool
+	redactOut bool
+
+	signOutput   bool
+	verifyOutput string
+	macKeyFile   string
+
+	separator string
+
+	confirmMainnet bool
+
+	showMasterKeys  bool
+	exportChildXprv bool
+	allowSensitive  bool
+	seedFormat      string
+
+	aezeedPassphrase string
+	aezeedBirthday   string
+
+	addresses          int
+	maxDerivationIndex int
+	maxIndex           int
+	force              bool
+	indices            string
+	change             int
+
+	mnemonic       string
+	wordIndices    string
+	mnemonicsFile  string
+	dedupe         bool
+	entropyFile    string
+	entropyExact   bool
+	passphraseFile string
+	stateFile      string
+
+	expectAddress string
+	xpub          string
+	xpubType      string
+	audit         bool
+	completeWord  bool
+	gapLimit      int
+
+	descriptors     bool
+	descriptorTypes string
+
+	showScriptPubKey bool
+	showDerivation   bool
+	tapTweakHash     bool
+	bip21            bool
+	bip21Amount      string
+	bip21Label       string
+	showTree         bool
+	showPathArray    bool
+	showWitness      bool
+
+	legacyBip32 bool
+
+	retryUntil  string
+	blocklist   string
+	maxAttempts int
+
+	primaryType string
+
+	hybridPubKey bool
+
+	paranoid bool
+
+	showSetHash bool
+
+	showIdenticon bool
+
+	sparrowLabels string
+	bip329Labels  string
+	labelTemplate string
+	perTypeFiles  string
+	sortOrder     string
+	perTypeTypes  string
+	fullAccount   string
+	coldcard      string
+	coreWallet    string
+	notifyURL     string
+
+	passphrases passphraseFlags
+
+	// Resolved from the flags by validate and validateBatch
+	params             *chaincfg.Params
+	passphrase         string
+	passphraseList     []string
+	macKey             []byte
+	strengths          []int
+	indexList          []uint32
+	descriptorTypeList []AddressType
+	perTypeList        []AddressType
+	notifier           *Notifier
+	labeler            Labeler
+	mnemonicList       []string
+	paymentRequest     *PaymentRequest
+	primary            *AddressType
+	accept             func(mnemonic string) bool
+	walletSeparator    string
+	birthday           time.Time
+
+	// state is loaded by deriveWallet and advanced to stateNext by saveState
+	// once the output is written, so a failed run does not skip addresses
+	state     *WalletState
+	stateNext uint32
+
+	// derive is deriveWallet, tests replace it to inject failures
+	derive func(i int, wallet *Wallet) (Generated, error)
+}
+
+// runGenerate parses the flags and generates the wallets, returning any error
+// so that deferred cleanup such as flushing output files runs before exiting.
+// It also serves the restore command and the flat flags of bare invocations.
+func runGenerate(name string, args []string) error {
+	c, err := parseGenerateFlags(name, args)
+	if err != nil {
+		return err
+	}
+
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	// These modes report on their input instead of generating wallets
+	switch {
+	case len(c.verifyOutput) > 0:
+		return c.runVerifyOutput()
+	case c.audit:
+		return c.runAudit()
+	case c.completeWord:
+		return c.runCompleteWord()
+	case len(c.expectAddress) > 0:
+		return c.runExpectAddress()
+	case len(c.xpub) > 0:
+		return c.runWatchOnly()
+	}
+
+	if err := c.validateBatch(); err != nil {
+		return err
+	}
+
+	if c.continuous {
+		return c.stream()
+	}
+
+	wallets, failed := c.batch()
+
+	if err := c.writeOutputs(wallets); err != nil {
+		return err
+	}
+
+	if err := c.saveState(); err != nil {
+		return err
+	}
+
+	return failed
+}
+
+// parseGenerateFlags parses the flags of the generate and restore commands
+func parseGenerateFlags(name string, args []string) (*generateCommand, error) {
+	c := &generateCommand{name: name, set: make(map[string]bool)}
+	c.derive = c.deriveWallet
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	logging := addLogFlags(fs)
+	registerSeedFlags(fs)
+
+	fs.IntVar(&c.bits, "bits", 128, "Bit size for entropy")
+	fs.StringVar(&c.words, "words", "", "Mnemonic word count: 12, 15, 18, 21 or 24 (alternative to -bits), a comma list is cycled across the batch")
+	fs.IntVar(&c.count, "count", 1, "Count of wallets to generate")
+	fs.StringVar(&c.out, "out", "", "Output file")
+	fs.BoolVar(&c.autoName, "auto-name", false, "Treat -out as a directory and name the file after the network and time, e.g. wallets-mainnet-20240601T120000.csv")
+	fs.StringVar(&c.network, "network", defaultNetwork, "Network: mainnet, testnet, regtest, signet, litecoin, liquid or liquidtestnet")
+	fs.StringVar(&c.hrp, "hrp", "", "Bech32 prefix of a private -network signet replacing tb, e.g. sb")
+	fs.StringVar(&c.paper, "paper", "", "Paper wallet HTML output file")
+	fs.BoolVar(&c.qr, "qr", false, "Report the QR code size of each mnemonic as encoded by -paper, use -words 12 for the most compact backup")
+	fs.StringVar(&c.format, "format", "", "Output format: text, table, csv, json or msgpack (default csv with -out, text otherwise)")
+	fs.BoolVar(&c.full, "full", false, "Show mnemonics in full in -format table instead of truncating them to the terminal width")
+
+	fs.IntVar(&c.countFrom, "count-from", 1, "Number of the first wallet in the # column of CSV and table output, the index of JSON and msgpack output and the -paper pages, to continue the numbering of an earlier run")
+	fs.BoolVar(&c.continuous, "continuous", false, "Generate wallets until SIGINT or SIGTERM instead of -count, writing each to -out as soon as it is generated")
+
+	fs.BoolVar(&c.redact, "redact", false, "Mask printed mnemonics as their first and last word, e.g. army ******** zoo, for screen sharing")
+	fs.BoolVar(&c.redactOut, "redact-out", false, "Also mask the mnemonics written to -out, the file can then no longer restore the wallets")
+
+	fs.BoolVar(&c.signOutput, "sign-output", false, "Write an HMAC-SHA256 of the -out file to a .hmac sidecar file (requires -mac-key-file)")
+	fs.StringVar(&c.verifyOutput, "verify-output", "", "Check a file against its .hmac sidecar file under -mac-key-file and exit")
+	fs.StringVar(&c.macKeyFile, "mac-key-file", "", "File holding the HMAC key of -sign-output and -verify-output")
+
+	fs.StringVar(&c.separator, "separator", `\n`, "Separator written between wallets in text output, supports Go escapes such as \\n")
+
+	fs.BoolVar(&c.confirmMainnet, "confirm-mainnet", false, "Confirm mainnet generation when guarded by BTC_WALLET_ALLOW_MAINNET")
+
+	fs.BoolVar(&c.showMasterKeys, "show-master-keys", false, "Include the BIP-32 master xprv and xpub (requires -allow-sensitive)")
+	fs.BoolVar(&c.exportChildXprv, "export-child-xprv", false, "Include the extended private key of each derived address for signing migrations (requires -allow-sensitive)")
+	fs.BoolVar(&c.allowSensitive, "allow-sensitive", false, "Allow exporting private key material beyond the mnemonic")
+	fs.StringVar(&c.seedFormat, "seed-format", SeedFormatMnemonic, "Secret written per wallet: mnemonic, hex (the BIP-39 seed), entropy-hex (both require -allow-sensitive) or aezeed (an lnd cipher seed, whose keys differ from any BIP-39 wallet)")
+	fs.StringVar(&c.aezeedPassphrase, "aezeed-passphrase", "", "Passphrase enciphering the -seed-format aezeed words, lnd uses \"aezeed\" when empty")
+	fs.StringVar(&c.aezeedBirthday, "aezeed-birthday", "", "Creation date YYYY-MM-DD of new -seed-format aezeed wallets, lnd rescans the chain from it (default today)")
+
+	fs.IntVar(&c.addresses, "addresses", 1, "Count of address indices to derive per wallet")
+	fs.IntVar(&c.maxDerivationIndex, "max-derivation-index", 100000, "Maximum count of address indices allowed without -force")
+	fs.IntVar(&c.maxIndex, "max-index", defaultMaxIndex, "Highest -indices value derived without -force, deeper indices are usually typos")
+	fs.BoolVar(&c.force, "force", false, "Allow deriving more addresses than -max-derivation-index or indices above -max-index")
+	fs.StringVar(&c.indices, "indices", "", "Comma separated address indices to derive, e.g. 0,7,42")
+	fs.IntVar(&c.change, "change", 0, "Chain to derive addresses from: 0 for receive, 1 for change, other values are non-standard")
+
+	fs.StringVar(&c.mnemonic, "mnemonic", "", "Restore the wallet from an existing mnemonic instead of generating one")
+	fs.StringVar(&c.wordIndices, "word-indices", "", "Restore the wallet from a space or comma separated list of BIP-39 word indices (0-2047) instead of words")
+	fs.StringVar(&c.mnemonicsFile, "mnemonics-file", "", "Restore one wallet per line of a file of mnemonics, blank lines and # comments are skipped")
+	fs.BoolVar(&c.dedupe, "dedupe", false, "Skip duplicate mnemonics in -mnemonics-file instead of failing")
+	fs.StringVar(&c.entropyFile, "entropy-file", "", "Generate the wallet from the first -bits/8 bytes of a raw entropy file, e.g. from a TRNG")
+	fs.BoolVar(&c.entropyExact, "entropy-file-exact", false, "Reject an -entropy-file longer than the required length instead of ignoring the rest")
+	fs.StringVar(&c.passphraseFile, "passphrase-file", "", "Read the BIP-39 passphrase from a file instead of -passphrase")
+	fs.StringVar(&c.stateFile, "state-file", "", "JSON file tracking the next address index of a restored wallet")
+
+	fs.StringVar(&c.expectAddress, "expect-address", "", "Find the network and path of a known address of the -mnemonic wallet")
+	fs.StringVar(&c.xpub, "xpub", "", "Derive watch-only addresses from an account xpub, ypub or zpub instead of a mnemonic")
+	fs.StringVar(&c.xpubType, "xpub-type", "", "Address type of a plain -xpub: p2pkh, p2sh-p2wpkh, p2wpkh or p2tr")
+	fs.BoolVar(&c.audit, "audit", false, "Only report whether -mnemonic or each line of -mnemonics-file is valid and its master fingerprint, without deriving addresses")
+	fs.BoolVar(&c.completeWord, "complete-word", false, "List the words completing a -mnemonic with one ? placeholder, filtered by -expect-address if set")
+	fs.IntVar(&c.gapLimit, "gap-limit", 20, "Count of address indices searched per type")
+
+	fs.BoolVar(&c.descriptors, "descriptors", false, "Include the receive and change output descriptors")
+	fs.StringVar(&c.descriptorTypes, "descriptor-types", "p2pkh,p2sh-p2wpkh,p2wpkh,p2tr", "Comma separated address types to export descriptors for")
+
+	fs.BoolVar(&c.showScriptPubKey, "show-scriptpubkey", false, "Include the hex scriptPubKey of each address")
+	fs.BoolVar(&c.showDerivation, "show-derivation", false, "Include the BIP-44 coin type and the purpose of each address type, so shared output is self-describing")
+	fs.BoolVar(&c.tapTweakHash, "tap-tweak-hash", false, "Include the TapTweak hash of each Taproot address, which tweaks the internal key into the output key")
+	fs.BoolVar(&c.bip21, "bip21", false, "Print addresses as BIP-21 bitcoin: URIs in text and CSV output, and encode the URIs in -paper QR codes")
+	fs.StringVar(&c.bip21Amount, "amount", "", "Amount in BTC requested by the -bip21 URIs, e.g. 0.001")
+	fs.StringVar(&c.bip21Label, "label", "", "Label of the -bip21 URIs")
+	fs.BoolVar(&c.showTree, "tree", false, "Include the derivation from the master key to each address as an ASCII tree (text output only)")
+	fs.BoolVar(&c.showPathArray, "show-path-array", false, "Include the derivation path of each address as the uint32 array of hardware wallet SDKs, e.g. [2147483732, 2147483648, 2147483648, 0, 0]")
+	fs.BoolVar(&c.showWitness, "show-witness", false, "Include the witness version and hex program of each bech32 address")
+
+	fs.BoolVar(&c.legacyBip32, "legacy-bip32", false, "Also derive the pre-BIP-44 m/0'/0/0 P2PKH address (recovery only)")
+
+	fs.StringVar(&c.retryUntil, "retry-until", "", "Regenerate each mnemonic until it satisfies a predicate: unambiguous")
+	fs.StringVar(&c.blocklist, "blocklist", "", "Comma separated words rejected by -retry-until unambiguous (default a built-in list of confusable words)")
+	fs.IntVar(&c.maxAttempts, "max-attempts", 1000, "Maximum mnemonics generated per wallet by -retry-until")
+
+	fs.StringVar(&c.primaryType, "primary-type", "", "Print only the address of this type, e.g. p2tr, one per line for $(btc-wallet ...) capture (requires -mnemonic)")
+
+	fs.BoolVar(&c.hybridPubKey, "hybrid-pubkey", false, "Derive the BIP-44 P2PKH addresses from hybrid encoded public keys, a non-standard format of very old software, for recovery only")
+
+	fs.BoolVar(&c.paranoid, "paranoid", false, "Re-decode every derived address and check it round-trips to the same string and type")
+
+	fs.BoolVar(&c.showSetHash, "show-set-hash", false, "Include a SHA-256 over the -addresses receive addresses of every type to compare runs or backups")
+
+	fs.BoolVar(&c.showIdenticon, "show-identicon", false, "Include a word identicon of the master fingerprint to visually compare devices")
+
+	fs.StringVar(&c.sparrowLabels, "sparrow-labels", "", "Sparrow labels CSV output file")
+	fs.StringVar(&c.bip329Labels, "bip329-labels", "", "BIP-329 wallet labels JSONL output file")
+	fs.StringVar(&c.labelTemplate, "label-template", "", "Label of -sparrow-labels and -bip329-labels addresses with placeholders {type}, {index}, {chain}, {account}, {path}, {network} and {wallet}, e.g. \"cold {type} #{index}\"")
+	fs.StringVar(&c.perTypeFiles, "per-type-files", "", "Directory to write one plain address list per type into, e.g. p2wpkh.txt and p2tr.txt")
+	fs.StringVar(&c.sortOrder, "sort", SortIndex, "Order of -per-type-files and -xpub address lists: index or addr (lexical, adding the wallet and index columns)")
+	fs.StringVar(&c.perTypeTypes, "per-type-types", "p2pkh,p2sh-p2wpkh,p2wpkh,p2tr", "Comma separated address types written by -per-type-files")
+	fs.StringVar(&c.fullAccount, "full-account", "", "Account snapshot CSV output file with the receive and change address of every type per index")
+	fs.StringVar(&c.coldcard, "coldcard", "", "Coldcard generic JSON export file for setting up an air-gapped signer")
+	fs.StringVar(&c.coreWallet, "core-wallet", "", "Bitcoin Core importdescriptors JSON file activating the receive and change descriptors of -descriptor-types in a watch-only descriptor wallet")
+	fs.StringVar(&c.notifyURL, "notify-url", "", "POST the derived addresses, never the mnemonics or keys, as JSON to this monitoring webhook, retrying on failure")
+
+	fs.Var(&c.passphrases, "passphrase", "Optional BIP-39 passphrase, repeat to derive one hidden wallet per passphrase from each mnemonic")
+
+	fs.Parse(args)
+
+	if err := logging.setup(); err != nil {
+		return nil, err
+	}
+
+	if err := applySecretEnv(fs, name == "restore"); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		c.set[f.Name] = true
+	})
+
+	return c, nil
+}
+
+// validate checks the flags shared by every mode and resolves the network,
+// passphrases, strengths and indices
+func (c *generateCommand) validate() error {
+	var err error
+
+	if len(c.passphrases) > 0 {
+		c.passphrase = c.passphrases[0]
+	}
+
+	if len(c.wordIndices) > 0 {
+		if len(c.mnemonic) > 0 {
+			return fmt.Errorf("-word-indices cannot be combined with -mnemonic")
+		}
+
+		restored, err := MnemonicFromIndices(c.wordIndices)
+		if err != nil {
+			return fmt.Errorf("invalid -word-indices: %w", err)
+		}
+		c.mnemonic = restored
+	}
+
+	if c.name == "restore" && len(c.mnemonic) == 0 && len(c.mnemonicsFile) == 0 {
+		return fmt.Errorf("restore requires -mnemonic, -word-indices, -mnemonics-file or %s", mnemonicEnv)
+	}
+
+	// An audit reports invalid mnemonics instead of failing on them
+	if len(c.mnemonic) > 0 && !c.audit {
+		if err := ValidateWordCount(c.mnemonic); err != nil {
+			return err
+		}
+	}
+
+	c.params, err = NetworkParams(c.network)
+	if err != nil {
+		return fmt.Errorf("error selecting network: %w", err)
+	}
+
+	if len(c.hrp) > 0 {
+		if c.network != "signet" {
+			return fmt.Errorf("-hrp only applies to -network signet")
+		}
+
+		c.params, err = SignetWithHRP(c.hrp)
+		if err != nil {
+			return fmt.Errorf("invalid -hrp: %w", err)
+		}
+	}
+
+	if err := CheckMainnetAllowed(c.params, c.confirmMainnet); err != nil {
+		return err
+	}
+
+	if IsLiquid(c.params) {
+		slog.Warn(LiquidWarning, "network", c.params.Name)
+	}
+
+	if c.autoName && len(c.out) == 0 {
+		return fmt.Errorf("-auto-name requires -out to be a directory")
+	}
+
+	if len(c.out) > 0 {
+		if err := ValidateOutputPath(c.out, c.autoName); err != nil {
+			return err
+		}
+	}
+
+	if (c.signOutput || len(c.verifyOutput) > 0) && len(c.macKeyFile) == 0 {
+		return fmt.Errorf("-sign-output and -verify-output require -mac-key-file")
+	}
+
+	if c.signOutput && len(c.out) == 0 {
+		return fmt.Errorf("-sign-output requires -out")
+	}
+
+	// The key is read before generating so a bad key file fails fast
+	if len(c.macKeyFile) > 0 {
+		c.macKey, err = ReadMACKey(c.macKeyFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.showMasterKeys && !c.allowSensitive {
+		return fmt.Errorf("refusing to output the master xprv without -allow-sensitive")
+	}
+
+	if c.exportChildXprv && !c.allowSensitive {
+		return fmt.Errorf("refusing to output child xprvs without -allow-sensitive")
+	}
+
+	if c.countFrom < 1 {
+		return fmt.Errorf("invalid -count-from %d: must be at least 1", c.countFrom)
+	}
+
+	if err := ValidateSeedFormat(c.seedFormat); err != nil {
+		return err
+	}
+
+	if c.seedFormat != SeedFormatMnemonic && c.seedFormat != SeedFormatAezeed && !c.allowSensitive {
+		return fmt.Errorf("refusing to output the %s seed format without -allow-sensitive", c.seedFormat)
+	}
+
+	if (c.redact || c.redactOut) && c.seedFormat != SeedFormatMnemonic && c.seedFormat != SeedFormatAezeed {
+		return fmt.Errorf("-redact only masks mnemonics, it cannot be combined with -seed-format %s", c.seedFormat)
+	}
+
+	// Masking the mnemonic is pointless next to the private keys
+	if (c.redact || c.redactOut) && (c.showMasterKeys || c.exportChildXprv) {
+		return fmt.Errorf("-redact cannot be combined with -show-master-keys or -export-child-xprv")
+	}
+
+	if c.redactOut && len(c.out) == 0 {
+		return fmt.Errorf("-redact-out requires -out")
+	}
+
+	if c.hybridPubKey {
+		slog.Warn("-hybrid-pubkey derives non-standard P2PKH addresses, only use them to recover funds already sent there")
+	}
+
+	if c.continuous {
+		if err := validateContinuous(c.set["count"], c.out, c.format); err != nil {
+			return err
+		}
+
+		// Every other input and output covers a fixed set of wallets
+		for _, name := range []string{"mnemonic", "mnemonics-file", "word-indices", "entropy-file", "xpub", "paper", "per-type-files", "full-account", "sparrow-labels", "bip329-labels", "coldcard", "core-wallet", "notify-url", "qr"} {
+			if c.set[name] {
+				return fmt.Errorf("-continuous cannot be combined with -%s", name)
+			}
+		}
+	}
+
+	c.strengths, err = validateStrength(c.count, c.bits, c.words, c.set["bits"])
+	if err != nil {
+		return err
+	}
+
+	if c.addresses < 1 {
+		return fmt.Errorf("invalid address count %d: must be at least 1", c.addresses)
+	}
+
+	if c.addresses > c.maxDerivationIndex && !c.force {
+		return fmt.Errorf("requested %d addresses per wallet but at most %d are allowed, use -force to override", c.addresses, c.maxDerivationIndex)
+	}
+
+	if err := ValidateChange(c.change); err != nil {
+		return err
+	}
+
+	if len(c.passphrases) > 1 && (len(c.passphraseFile) > 0 || len(c.stateFile) > 0 || len(c.expectAddress) > 0 || c.completeWord) {
+		return fmt.Errorf("multiple -passphrase flags cannot be combined with -passphrase-file, -state-file, -expect-address or -complete-word")
+	}
+
+	if err := resolvePassphrase(&c.passphrase, c.passphraseFile); err != nil {
+		return err
+	}
+
+	// The first passphrase may come from -passphrase-file, the hidden wallets
+	// of the other passphrases share its mnemonic
+	c.passphraseList = []string{c.passphrase}
+	if len(c.passphrases) > 1 {
+		c.passphraseList = append(c.passphraseList, c.passphrases[1:]...)
+	}
+
+	if err := c.validateAezeed(); err != nil {
+		return err
+	}
+
+	if len(c.indices) > 0 {
+		if c.addresses > 1 || len(c.stateFile) > 0 {
+			return fmt.Errorf("-indices cannot be combined with -addresses or -state-file")
+		}
+
+		c.indexList, err = ParseIndices(c.indices)
+		if err != nil {
+			return fmt.Errorf("invalid -indices: %w", err)
+		}
+
+		if c.maxIndex < 0 {
+			return fmt.Errorf("invalid -max-index %d: must not be negative", c.maxIndex)
+		}
+
+		if err := CheckIndexThreshold(c.indexList, uint32(c.maxIndex), c.force); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidateSort(c.sortOrder); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateAezeed checks the flags of -seed-format aezeed and resolves the
+// birthday. The aezeed passphrase only enciphers the seed, the BIP-39
+// passphrase and the other mnemonic inputs have no place in the lnd scheme.
+func (c *generateCommand) validateAezeed() error {
+	if c.seedFormat != SeedFormatAezeed {
+		if len(c.aezeedPassphrase) > 0 || len(c.aezeedBirthday) > 0 {
+			return fmt.Errorf("-aezeed-passphrase and -aezeed-birthday require -seed-format aezeed")
+		}
+
+		return nil
+	}
+
+	if len(c.passphrase) > 0 || len(c.passphraseList) > 1 {
+		return fmt.Errorf("-seed-format aezeed is enciphered with -aezeed-passphrase, a BIP-39 passphrase does not apply")
+	}
+
+	for _, name := range []string{"mnemonics-file", "word-indices", "entropy-file", "retry-until", "bits", "words", "audit", "complete-word", "expect-address"} {
+		if c.set[name] {
+			return fmt.Errorf("-seed-format aezeed cannot be combined with -%s", name)
+		}
+	}
+
+	c.birthday = time.Now()
+	if len(c.aezeedBirthday) > 0 {
+		if len(c.mnemonic) > 0 {
+			return fmt.Errorf("-aezeed-birthday only applies to new wallets, a restored aezeed carries its own")
+		}
+
+		var err error
+		c.birthday, err = time.Parse(time.DateOnly, c.aezeedBirthday)
+		if err != nil {
+			return fmt.Errorf("invalid -aezeed-birthday: %w", err)
+		}
+	}
+
+	if _, err := AezeedBirthday(c.birthday); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runVerifyOutput checks -verify-output against its .hmac sidecar file
+func (c *generateCommand) runVerifyOutput() error {
+	if err := VerifyFileMAC(c.verifyOutput, c.macKey); err != nil {
+		return err
+	}
+
+	fmt.Println("HMAC verified:", c.verifyOutput)
+	return nil
+}
+
+// runAudit reports whether -mnemonic or each line of -mnemonics-file is valid
+func (c *generateCommand) runAudit() error {
+	var results []AuditResult
+	switch {
+	case len(c.mnemonic) > 0:
+		results = append(results, AuditMnemonic(c.mnemonic, c.passphrase, c.params))
+	case len(c.mnemonicsFile) > 0:
+		// Read every line, duplicates and invalid mnemonics are what an
+		// audit is looking for
+		lines, err := ReadMnemonicLines(c.mnemonicsFile)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			result := AuditMnemonic(line.Mnemonic, c.passphrase, c.params)
+			result.Line = line.Line
+			results = append(results, result)
+		}
+	default:
+		return fmt.Errorf("-audit requires -mnemonic or -mnemonics-file")
+	}
+
+	WriteAudit(os.Stdout, results)
+
+	invalid := 0
+	for _, result := range results {
+		if result.Err != nil {
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d mnemonics are invalid", invalid, len(results))
+	}
+
+	return nil
+}
+
+// runCompleteWord lists the words completing a -mnemonic with one placeholder
+func (c *generateCommand) runCompleteWord() error {
+	if len(c.mnemonic) == 0 {
+		return fmt.Errorf("-complete-word requires a -mnemonic with a %q placeholder", MissingWordPlaceholder)
+	}
+
+	candidates, err := MissingWordCandidates(c.mnemonic, func(done int, total int) {
+		slog.Info("searching missing word", "checked", done, "total", total)
+	})
+	if err != nil {
+		return fmt.Errorf("error completing mnemonic: %w", err)
+	}
+
+	// Each candidate costs a full seed derivation, so the address filter
+	// only runs on the candidates with a valid checksum
+	if len(c.expectAddress) > 0 {
+		var matching []string
+		for i, candidate := range candidates {
+			match, err := FindAddress(candidate, c.passphrase, c.params, c.expectAddress, uint32(c.gapLimit))
+			if err != nil {
+				return fmt.Errorf("error finding address: %w", err)
+			}
+
+			if match != nil {
+				slog.Info("found address", "type", match.Type.Name(), "path", match.Path)
+				matching = append(matching, candidate)
+			}
+
+			slog.Debug("checked candidate", "checked", i+1, "total", len(candidates))
+		}
+		candidates = matching
+	}
+
+	if len(candidates) == 0 {
+		return fmt.Errorf("no word completes the mnemonic")
+	}
+
+	for _, candidate := range candidates {
+		fmt.Println(candidate)
+	}
+
+	return nil
+}
+
+// runExpectAddress finds the network and path of a known address of the
+// -mnemonic wallet
+func (c *generateCommand) runExpectAddress() error {
+	if len(c.mnemonic) == 0 {
+		return fmt.Errorf("-expect-address requires restoring a wallet with -mnemonic")
+	}
+
+	match, err := FindAddressNetwork(c.mnemonic, c.passphrase, c.expectAddress, uint32(c.gapLimit), c.confirmMainnet)
+	if err != nil {
+		return fmt.Errorf("error finding address: %w", err)
+	}
+
+	fmt.Printf("Found %s address on network %s at path %s\n", match.Type, match.Network, match.Path)
+	return nil
+}
+
+// runWatchOnly writes the addresses of an account -xpub
+func (c *generateCommand) runWatchOnly() error {
+	if len(c.mnemonic) > 0 {
+		return fmt.Errorf("-xpub cannot be combined with -mnemonic")
+	}
+
+	if c.qr {
+		return fmt.Errorf("-qr requires mnemonics, an -xpub has none")
+	}
+
+	return WriteWatchOnlyAddresses(os.Stdout, c.xpub, c.xpubType, c.params, uint32(c.change), indexRange(c.indexList, c.addresses), c.sortOrder)
+}
+
+// validateBatch checks the flags of generating or restoring a batch of wallets
+// and resolves the inputs and outputs of the batch
+func (c *generateCommand) validateBatch() error {
+	var err error
+
+	if c.descriptors || len(c.coreWallet) > 0 {
+		c.descriptorTypeList, err = ParseAddressTypes(c.descriptorTypes)
+		if err != nil {
+			return fmt.Errorf("invalid -descriptor-types: %w", err)
+		}
+	}
+
+	if len(c.perTypeFiles) > 0 {
+		c.perTypeList, err = ParseAddressTypes(c.perTypeTypes)
+		if err != nil {
+			return fmt.Errorf("invalid -per-type-types: %w", err)
+		}
+
+		info, err := os.Stat(c.perTypeFiles)
+		if err != nil {
+			return fmt.Errorf("error opening -per-type-files directory: %w", err)
+		}
+
+		if !info.IsDir() {
+			return fmt.Errorf("-per-type-files must be a directory, %s is not", c.perTypeFiles)
+		}
+	}
+
+	if len(c.notifyURL) > 0 {
+		c.notifier, err = NewNotifier(c.notifyURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(c.labelTemplate) > 0 {
+		if len(c.sparrowLabels) == 0 && len(c.bip329Labels) == 0 {
+			return fmt.Errorf("-label-template requires -sparrow-labels or -bip329-labels")
+		}
+
+		c.labeler, err = ParseLabelTemplate(c.labelTemplate, c.params)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(c.mnemonic) > 0 && c.count != 1 {
+		return fmt.Errorf("only a single wallet can be restored from -mnemonic, got -count %d", c.count)
+	}
 
-	flag.Parse()
+	if len(c.mnemonicsFile) > 0 {
+		if len(c.mnemonic) > 0 || len(c.retryUntil) > 0 || len(c.entropyFile) > 0 {
+			return fmt.Errorf("-mnemonics-file cannot be combined with -mnemonic, -retry-until or -entropy-file")
+		}
 
-	var wallets []Generated
+		c.mnemonicList, err = LoadMnemonics(c.mnemonicsFile, c.dedupe)
+		if err != nil {
+			return err
+		}
+
+		// The file decides the batch size
+		c.count = len(c.mnemonicList)
+		slog.Debug("loaded mnemonics", "file", c.mnemonicsFile, "count", c.count)
+	}
+
+	if c.bip21 {
+		if c.format == "json" || c.format == "msgpack" {
+			return fmt.Errorf("-bip21 only applies to text and CSV output and -paper")
+		}
+
+		// Litecoin and Liquid have their own URI schemes
+		if c.params.Name == "litecoin" || IsLiquid(c.params) {
+			return fmt.Errorf("-bip21 creates bitcoin: URIs and cannot be used with -network %s", c.params.Name)
+		}
+
+		c.paymentRequest = &PaymentRequest{Label: c.bip21Label}
+		if len(c.bip21Amount) > 0 {
+			c.paymentRequest.Amount, err = ParseBIP21Amount(c.bip21Amount)
+			if err != nil {
+				return fmt.Errorf("invalid -amount: %w", err)
+			}
+		}
+	} else if len(c.bip21Amount) > 0 || len(c.bip21Label) > 0 {
+		return fmt.Errorf("-amount and -label require -bip21")
+	}
+
+	// Output defaults to CSV with -out, which has no room for the tree
+	if c.showTree && c.format != "text" && (len(c.format) > 0 || len(c.out) > 0) {
+		return fmt.Errorf("-tree only applies to text output, add -format text")
+	}
 
-	for i := 0; i < *count; i++ {
-		wallet, err := NewWallet(*bits)
+	if len(c.primaryType) > 0 {
+		t, err := ParseAddressType(c.primaryType)
 		if err != nil {
-			log.Fatalf("Error generating wallet: %v", err)
+			return fmt.Errorf("invalid -primary-type: %w", err)
+		}
+
+		// The bare address line omits the mnemonic, so it would discard a
+		// newly generated wallet
+		if len(c.mnemonic) == 0 && len(c.mnemonicList) == 0 {
+			return fmt.Errorf("-primary-type prints no mnemonic and requires restoring with -mnemonic or -mnemonics-file")
+		}
+
+		if len(c.format) > 0 && c.format != "text" {
+			return fmt.Errorf("-primary-type only applies to text output, got -format %s", c.format)
+		}
+
+		if c.showTree {
+			return fmt.Errorf("-tree cannot be combined with -primary-type")
+		}
+
+		c.format = "text"
+		c.primary = &t
+	}
+
+	if len(c.entropyFile) > 0 {
+		if len(c.mnemonic) > 0 || len(c.retryUntil) > 0 {
+			return fmt.Errorf("-entropy-file cannot be combined with -mnemonic or -retry-until")
+		}
+
+		if c.count != 1 || len(c.strengths) != 1 {
+			return fmt.Errorf("-entropy-file generates a single wallet of one strength, got -count %d", c.count)
+		}
+	}
+
+	if len(c.coldcard) > 0 && c.count != 1 {
+		return fmt.Errorf("-coldcard exports a single wallet, got -count %d", c.count)
+	}
+
+	if len(c.coreWallet) > 0 && c.count != 1 {
+		return fmt.Errorf("-core-wallet exports a single wallet, got -count %d", c.count)
+	}
+
+	if len(c.stateFile) > 0 && len(c.mnemonic) == 0 {
+		return fmt.Errorf("-state-file requires restoring a wallet with -mnemonic")
+	}
+
+	if len(c.stateFile) > 0 && c.change != 0 {
+		return fmt.Errorf("-state-file only tracks the receive chain and cannot be combined with -change")
+	}
+
+	if len(c.retryUntil) > 0 {
+		predicate, ok := mnemonicPredicates[c.retryUntil]
+		if !ok {
+			return fmt.Errorf("unknown -retry-until predicate %q", c.retryUntil)
+		}
+
+		if len(c.mnemonic) > 0 {
+			return fmt.Errorf("-retry-until cannot be combined with -mnemonic")
+		}
+
+		if c.maxAttempts < 1 {
+			return fmt.Errorf("invalid -max-attempts %d: must be at least 1", c.maxAttempts)
+		}
+
+		words := confusableWords
+		if len(c.blocklist) > 0 {
+			words = nil
+			for _, word := range strings.Split(c.blocklist, ",") {
+				words = append(words, strings.TrimSpace(word))
+			}
+		}
+
+		c.accept = predicate(words)
+	}
+
+	c.walletSeparator, err = strconv.Unquote(`"` + c.separator + `"`)
+	if err != nil {
+		return fmt.Errorf("invalid -separator %q: %w", c.separator, err)
+	}
+
+	if len(c.format) == 0 {
+		c.format = "text"
+		if len(c.out) > 0 {
+			c.format = "csv"
+		}
+	}
+
+	return nil
+}
+
+// newWallet creates the i-th wallet of the batch under the first passphrase
+func (c *generateCommand) newWallet(i int) (*Wallet, error) {
+	var wallet *Wallet
+	var err error
+	if c.seedFormat == SeedFormatAezeed {
+		wallet, err = c.newAezeedWallet()
+	} else if len(c.mnemonicList) > 0 {
+		wallet, err = NewWalletFromMnemonic(c.mnemonicList[i], c.passphrase, c.params)
+	} else if len(c.mnemonic) > 0 {
+		wallet, err = NewWalletFromMnemonic(c.mnemonic, c.passphrase, c.params)
+	} else if len(c.entropyFile) > 0 {
+		var entropy []byte
+		entropy, err = ReadEntropyFile(c.entropyFile, c.strengths[0], c.entropyExact)
+		if err == nil {
+			wallet, err = NewWalletFromEntropy(entropy, c.passphrase, c.params)
+			clear(entropy)
+		}
+	} else if c.accept != nil {
+		var attempts int
+		wallet, attempts, err = NewWalletUntil(c.strengths[i%len(c.strengths)], c.passphrase, c.params, c.accept, c.maxAttempts)
+		slog.Info("generated mnemonic", "wallet", i+1, "predicate", c.retryUntil, "attempts", attempts)
+	} else {
+		wallet, err = NewWallet(c.strengths[i%len(c.strengths)], c.passphrase, c.params)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error generating wallet: %w", err)
+	}
+
+	slog.Debug("generated wallet", "wallet", i+1, "network", c.params.Name)
+
+	return wallet, nil
+}
+
+// newAezeedWallet restores the -mnemonic aezeed or creates a new one born on
+// -aezeed-birthday
+func (c *generateCommand) newAezeedWallet() (*Wallet, error) {
+	var seed *Aezeed
+	var err error
+	if len(c.mnemonic) > 0 {
+		seed, err = ParseAezeed(c.mnemonic, c.aezeedPassphrase)
+	} else {
+		var entropy [aezeedEntropySize]byte
+		if _, err := rand.Read(entropy[:]); err != nil {
+			return nil, fmt.Errorf("error generating entropy: %w", err)
 		}
 
-		// Derive and print the BIP-44 P2PKH address
-		p2pkhAddress, err := wallet.DeriveP2PKHAddress()
+		seed, err = NewAezeed(entropy, c.birthday)
+		clear(entropy[:])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWalletFromAezeed(seed, c.aezeedPassphrase, c.params)
+}
+
+// deriveWallet derives the addresses of the i-th wallet of the batch
+func (c *generateCommand) deriveWallet(i int, wallet *Wallet) (Generated, error) {
+	wallet.HybridPubKey = c.hybridPubKey
+
+	var err error
+	var state *WalletState
+	var start, next uint32
+	if len(c.stateFile) > 0 {
+		state, err = LoadState(c.stateFile)
 		if err != nil {
-			log.Fatalf("Error deriving BIP-44 P2PKH address: %v", err)
+			return Generated{}, fmt.Errorf("error loading state: %w", err)
 		}
 
-		// Derive and print the BIP-49 P2WPKH-in-P2SH address
-		p2wpkhP2shAddress, err := wallet.DeriveP2WPKHInP2SHAddress()
+		fingerprint, err := wallet.Fingerprint()
 		if err != nil {
-			log.Fatalf("Error deriving BIP-49 P2WPKH-in-P2SH address: %v", err)
+			return Generated{}, fmt.Errorf("error computing fingerprint: %w", err)
+		}
+
+		if err := state.Bind(hex.EncodeToString(fingerprint)); err != nil {
+			return Generated{}, fmt.Errorf("error loading state: %w", err)
 		}
 
-		// Derive and print the BIP-84 native SegWit (P2WPKH) address
-		p2wpkhAddress, err := wallet.DeriveP2WPKHAddress()
+		start, next, err = state.Range(c.addresses)
 		if err != nil {
-			log.Fatalf("Error deriving BIP-84 native SegWit address: %v", err)
+			return Generated{}, err
 		}
+	}
+
+	// Derive and print the BIP-44 P2PKH address
+	p2pkhAddress, err := wallet.DeriveP2PKHAddress(uint32(c.change), 0)
+	if err != nil {
+		return Generated{}, fmt.Errorf("error deriving BIP-44 P2PKH address: %w", err)
+	}
+
+	// Derive and print the BIP-49 P2WPKH-in-P2SH address
+	p2wpkhP2shAddress, err := wallet.DeriveP2WPKHInP2SHAddress(uint32(c.change), 0)
+	if err != nil {
+		return Generated{}, fmt.Errorf("error deriving BIP-49 P2WPKH-in-P2SH address: %w", err)
+	}
 
-		// Derive and print the Taproot address
-		taprootAddress, err := wallet.DeriveTaprootAddress()
+	// Derive and print the BIP-84 native SegWit (P2WPKH) address
+	p2wpkhAddress, err := wallet.DeriveP2WPKHAddress(uint32(c.change), 0)
+	if err != nil {
+		return Generated{}, fmt.Errorf("error deriving BIP-84 native SegWit address: %w", err)
+	}
+
+	// Derive and print the Taproot address
+	taprootAddress, err := wallet.DeriveTaprootAddress(uint32(c.change), 0, nil)
+	if err != nil {
+		return Generated{}, fmt.Errorf("error deriving Taproot address: %w", err)
+	}
+
+	var legacyBip32Address btcutil.Address
+	if c.legacyBip32 {
+		legacyBip32Address, err = wallet.DeriveLegacyBIP32Address(0)
 		if err != nil {
-			log.Fatalf("Error deriving Taproot address: %v", err)
+			return Generated{}, fmt.Errorf("error deriving BIP-32 legacy address: %w", err)
+		}
+	}
+
+	var addressSets []AddressSet
+	if len(c.indexList) > 0 {
+		for _, index := range c.indexList {
+			set, err := wallet.DeriveAll(uint32(c.change), index)
+			if err != nil {
+				return Generated{}, fmt.Errorf("error deriving addresses at index %d: %w", index, err)
+			}
+
+			addressSets = append(addressSets, set)
+		}
+	} else if c.addresses > 1 || state != nil {
+		for index := start; index < start+uint32(c.addresses); index++ {
+			set, err := wallet.DeriveAll(uint32(c.change), index)
+			if err != nil {
+				return Generated{}, fmt.Errorf("error deriving addresses at index %d: %w", index, err)
+			}
+
+			addressSets = append(addressSets, set)
 		}
+	}
 
-		wallets = append(wallets, Generated{
-			P2pkhAddress:      p2pkhAddress,
-			P2wpkhP2shAddress: p2wpkhP2shAddress,
-			P2wpkhAddress:     p2wpkhAddress,
-			TaprootAddress:    taprootAddress,
-			Mnemonic:          wallet.Mnemonic,
-		})
+	if state != nil {
+		c.state, c.stateNext = state, next
 	}
 
-	if len(*out) > 0 {
-		fileName := *out
+	masterXpub, err := wallet.MasterKey.Neuter()
+	if err != nil {
+		return Generated{}, fmt.Errorf("error deriving master xpub: %w", err)
+	}
 
-		file, err := os.Create(fileName)
+	var walletDescriptors []WalletDescriptor
+	if c.descriptors {
+		walletDescriptors, err = wallet.Descriptors(c.descriptorTypeList)
 		if err != nil {
-			fmt.Println("Error creating file:", err)
-			return
+			return Generated{}, fmt.Errorf("error building descriptors: %w", err)
 		}
-		defer file.Close()
+	}
 
-		writer := csv.NewWriter(file)
-		defer writer.Flush() // Ensure all data is written to the file
+	var identicon string
+	if c.showIdenticon {
+		fingerprint, err := wallet.Fingerprint()
+		if err != nil {
+			return Generated{}, fmt.Errorf("error computing fingerprint: %w", err)
+		}
 
-		header := []string{"#", "Legacy, BIP-44 P2PKH Address", "Nested Segwit, BIP-49 P2WPKH-in-P2SH Address", "Native Segwit, BIP-84 P2WPKH Address", "Taproot, BIP-86 P2TR Address", "Mnemonic"}
+		identicon = Identicon(fingerprint)
+	}
 
-		if err := writer.Write(header); err != nil {
-			fmt.Println("Error writing header to file:", err)
-			return
+	var tapTweak []byte
+	if c.tapTweakHash {
+		tapTweak, err = wallet.TapTweak(uint32(c.change), 0)
+		if err != nil {
+			return Generated{}, fmt.Errorf("error computing Taproot tweak: %w", err)
 		}
 
-		for i, wallet := range wallets {
-			row := []string{
-				strconv.Itoa(i + 1),
-				wallet.P2pkhAddress.EncodeAddress(),
-				wallet.P2wpkhP2shAddress.EncodeAddress(),
-				wallet.P2wpkhAddress.EncodeAddress(),
-				wallet.TaprootAddress.EncodeAddress(),
-				wallet.Mnemonic,
+		for i := range addressSets {
+			addressSets[i].TapTweak, err = wallet.TapTweak(addressSets[i].Change, addressSets[i].Index)
+			if err != nil {
+				return Generated{}, fmt.Errorf("error computing Taproot tweak: %w", err)
 			}
+		}
+	}
+
+	var childXprvs map[AddressType]string
+	if c.exportChildXprv {
+		childXprvs, err = wallet.ChildXprvs(uint32(c.change), 0)
+		if err != nil {
+			return Generated{}, fmt.Errorf("error exporting child xprvs: %w", err)
+		}
 
-			if err := writer.Write(row); err != nil {
-				fmt.Println("Error writing record to file:", err)
-				return
+		for i := range addressSets {
+			addressSets[i].ChildXprvs, err = wallet.ChildXprvs(addressSets[i].Change, addressSets[i].Index)
+			if err != nil {
+				return Generated{}, fmt.Errorf("error exporting child xprvs: %w", err)
 			}
 		}
+	}
 
-		fmt.Println("Saved to:", *out)
+	var setHash string
+	if c.showSetHash {
+		hash, err := wallet.AddressSetHash(AddressTypes, uint32(c.addresses))
+		if err != nil {
+			return Generated{}, fmt.Errorf("error hashing address set: %w", err)
+		}
 
-	} else {
-		for i, wallet := range wallets {
-			fmt.Println("Mnemonic:", wallet.Mnemonic)
+		setHash = hex.EncodeToString(hash)
+	}
+
+	var tree string
+	if c.showTree {
+		treeIndices := []uint32{0}
+		if len(addressSets) > 0 {
+			treeIndices = nil
+			for _, set := range addressSets {
+				treeIndices = append(treeIndices, set.Index)
+			}
+		}
+
+		root, err := wallet.DerivationTree(AddressTypes, uint32(c.change), treeIndices)
+		if err != nil {
+			return Generated{}, err
+		}
+
+		tree = root.Render()
+	}
+
+	var fullAccountRows []AccountRow
+	if len(c.fullAccount) > 0 {
+		fullAccountRows, err = wallet.DeriveFullAccount(indexRange(c.indexList, c.addresses))
+		if err != nil {
+			return Generated{}, err
+		}
+	}
+
+	var coldcardExport *ColdcardExport
+	if len(c.coldcard) > 0 {
+		coldcardExport, err = wallet.ColdcardExport()
+		if err != nil {
+			return Generated{}, fmt.Errorf("error building Coldcard export: %w", err)
+		}
+	}
 
-			fmt.Println("BIP-44 P2PKH Address:", wallet.P2pkhAddress)
+	var coreImport []CoreImportRequest
+	if len(c.coreWallet) > 0 {
+		coreImport, err = wallet.CoreWallet(c.descriptorTypeList, len(c.mnemonic) > 0)
+		if err != nil {
+			return Generated{}, fmt.Errorf("error building Bitcoin Core wallet export: %w", err)
+		}
+	}
+
+	var secret string
+	if c.seedFormat != SeedFormatMnemonic {
+		secret, err = wallet.SeedSecret(c.seedFormat)
+		if err != nil {
+			return Generated{}, err
+		}
+	}
+
+	purposes := make(map[AddressType]uint32)
+	for _, t := range AddressTypes {
+		purposes[t] = t.Purpose()
+	}
+
+	generated := Generated{
+		P2pkhAddress:      p2pkhAddress,
+		P2wpkhP2shAddress: p2wpkhP2shAddress,
+		P2wpkhAddress:     p2wpkhAddress,
+		TaprootAddress:    taprootAddress,
+		LegacyBip32:       legacyBip32Address,
+		Mnemonic:          wallet.Mnemonic,
+		Secret:            secret,
+		MasterXprv:        wallet.MasterKey.String(),
+		MasterXpub:        masterXpub.String(),
+		Identicon:         identicon,
+		CoinType:          c.params.HDCoinType,
+		Purposes:          purposes,
+		FullAccount:       fullAccountRows,
+		TapTweak:          tapTweak,
+		ChildXprvs:        childXprvs,
+		SetHash:           setHash,
+		Tree:              tree,
+		Change:            uint32(c.change),
+		Addresses:         addressSets,
+		Descriptors:       walletDescriptors,
+		Coldcard:          coldcardExport,
+		CoreWallet:        coreImport,
+	}
+
+	if c.paranoid {
+		for _, set := range generated.AddressSets() {
+			if err := set.CheckRoundTrip(c.params); err != nil {
+				return Generated{}, fmt.Errorf("paranoid check failed: %w", err)
+			}
+		}
+
+		slog.Debug("paranoid check passed", "wallet", i+1)
+	}
+
+	return generated, nil
+}
+
+// walletsAt generates the i-th wallet of the batch, followed by a hidden
+// wallet per additional passphrase, along with the failures
+func (c *generateCommand) walletsAt(i int) ([]Generated, []error) {
+	wallet, err := c.newWallet(i)
+	if err != nil {
+		slog.Warn("failed to generate wallet", "wallet", i+1, "error", err)
+		return nil, []error{fmt.Errorf("wallet %d: %w", i+1, err)}
+	}
+
+	var generatedWallets []Generated
+	var failures []error
+
+	// Passphrases are only ever referred to by their index, the values
+	// must not appear in the output or the logs
+	seen := make(map[string]int)
+	for j, hidden := range c.passphraseList {
+		if j > 0 {
+			wallet, err = NewWalletFromMnemonic(wallet.Mnemonic, hidden, c.params)
+			if err != nil {
+				failures = append(failures, fmt.Errorf("wallet %d passphrase #%d: %w", i+1, j+1, err))
+				break
+			}
+		}
+
+		generated, err := c.derive(i, wallet)
+		if err != nil {
+			slog.Warn("failed to generate wallet", "wallet", i+1, "passphrase_index", j+1, "error", err)
+			failures = append(failures, fmt.Errorf("wallet %d: %w", i+1, err))
+			continue
+		}
+
+		address := generated.P2wpkhAddress.EncodeAddress()
+		if first, ok := seen[address]; ok {
+			failures = append(failures, fmt.Errorf("wallet %d: passphrase #%d derives the same addresses as passphrase #%d", i+1, j+1, first))
+			continue
+		}
+		seen[address] = j + 1
+
+		generated.PassphraseIndex = j + 1
+		generatedWallets = append(generatedWallets, generated)
+	}
+
+	return generatedWallets, failures
+}
+
+// batch generates the -count wallets of the batch. It keeps generating after a
+// failure so a single bad wallet does not discard the rest of the batch, the
+// returned error of the failures is reported once the output is written.
+func (c *generateCommand) batch() ([]Generated, error) {
+	var wallets []Generated
+	var failures []error
+
+	for i := 0; i < c.count; i++ {
+		generatedWallets, walletFailures := c.walletsAt(i)
+		wallets = append(wallets, generatedWallets...)
+		failures = append(failures, walletFailures...)
+	}
+
+	if len(failures) > 0 {
+		// Every wallet attempted, one per passphrase, either succeeded or
+		// failed once
+		attempted := len(wallets) + len(failures)
+		return wallets, fmt.Errorf("%d of %d wallets failed:\n%w", len(failures), attempted, errors.Join(failures...))
+	}
+
+	return wallets, nil
+}
+
+// saveState records the addresses issued by the run in the -state-file
+func (c *generateCommand) saveState() error {
+	if c.state == nil {
+		return nil
+	}
+
+	c.state.Advance(c.stateNext)
 
-			fmt.Println("BIP-49 P2WPKH-in-P2SH Address:", wallet.P2wpkhP2shAddress)
+	if err := c.state.Save(c.stateFile); err != nil {
+		return fmt.Errorf("error saving state: %w", err)
+	}
+
+	slog.Debug("saved state", "file", c.stateFile, "next_index", c.stateNext)
+
+	return nil
+}
 
-			fmt.Println("BIP-84 P2WPKH Address:", wallet.P2wpkhAddress)
+// writeOutputs writes the batch to the side files, then to -out or stdout, and
+// notifies -notify-url
+func (c *generateCommand) writeOutputs(wallets []Generated) error {
+	if len(c.paper) > 0 {
+		if err := WritePaperWallets(c.paper, c.params.Name, c.countFrom, wallets, c.paymentRequest); err != nil {
+			return fmt.Errorf("error writing paper wallet: %w", err)
+		}
 
-			fmt.Println("BIP-86 P2TR Address:", wallet.TaprootAddress)
+		fmt.Println("Saved paper wallet to:", c.paper)
+	}
 
-			if i != *count-1 {
-				fmt.Println("")
+	if c.qr {
+		for i, wallet := range wallets {
+			version, modules, err := MnemonicQRSize(wallet.Mnemonic)
+			if err != nil {
+				return err
 			}
+
+			fmt.Printf("Mnemonic QR of wallet #%d: %d words, version %d, %dx%d modules\n", i+1, len(strings.Fields(wallet.Mnemonic)), version, modules, modules)
+		}
+	}
+
+	if len(c.perTypeFiles) > 0 {
+		if err := WriteTypeFiles(c.perTypeFiles, c.perTypeList, wallets, c.sortOrder); err != nil {
+			return err
+		}
+
+		for _, t := range c.perTypeList {
+			fmt.Printf("Saved %s addresses to: %s\n", t.Name(), TypeFileName(c.perTypeFiles, t))
+		}
+	}
+
+	if len(c.fullAccount) > 0 {
+		if err := WriteFullAccount(c.fullAccount, wallets); err != nil {
+			return fmt.Errorf("error writing account snapshot: %w", err)
+		}
+
+		fmt.Println("Saved account snapshot to:", c.fullAccount)
+	}
+
+	if len(c.sparrowLabels) > 0 {
+		if err := WriteSparrowLabels(c.sparrowLabels, wallets, c.labeler); err != nil {
+			return fmt.Errorf("error writing Sparrow labels: %w", err)
 		}
+
+		fmt.Println("Saved Sparrow labels to:", c.sparrowLabels)
+	}
+
+	if len(c.bip329Labels) > 0 {
+		if err := WriteBIP329Labels(c.bip329Labels, wallets, c.labeler); err != nil {
+			return fmt.Errorf("error writing BIP-329 labels: %w", err)
+		}
+
+		fmt.Println("Saved BIP-329 labels to:", c.bip329Labels)
+	}
+
+	if len(c.coldcard) > 0 && len(wallets) > 0 {
+		if err := WriteColdcardExport(c.coldcard, wallets); err != nil {
+			return fmt.Errorf("error writing Coldcard export: %w", err)
+		}
+
+		fmt.Println("Saved Coldcard export to:", c.coldcard)
+	}
+
+	if len(c.coreWallet) > 0 && len(wallets) > 0 {
+		if err := WriteCoreWallet(c.coreWallet, wallets); err != nil {
+			return fmt.Errorf("error writing Bitcoin Core wallet export: %w", err)
+		}
+
+		fmt.Println("Saved Bitcoin Core wallet export to:", c.coreWallet)
+	}
+
+	opts := c.outputOptions()
+
+	if len(c.out) == 0 {
+		if err := WriteWallets(os.Stdout, c.format, wallets, opts); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+	} else {
+		file, err := c.createOutput()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		// The file keeps the full mnemonics unless masking it was asked for
+		// separately, -redact only covers what is shown on screen
+		opts.Redact = c.redactOut
+		if err := WriteWallets(file, c.format, wallets, opts); err != nil {
+			return fmt.Errorf("error writing to file: %w", err)
+		}
+
+		if err := c.closeOutput(file); err != nil {
+			return err
+		}
+	}
+
+	// Notify last, so the addresses are only monitored once safely written
+	if c.notifier != nil && len(wallets) > 0 {
+		if err := c.notifier.NotifyAddresses(wallets, c.params); err != nil {
+			return fmt.Errorf("error notifying -notify-url: %w", err)
+		}
+
+		slog.Info("notified addresses", "wallets", len(wallets))
+	}
+
+	return nil
+}
+
+// stream generates wallets into the -out file until SIGINT or SIGTERM, see
+// StreamUntilSignal
+func (c *generateCommand) stream() error {
+	file, err := c.createOutput()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	opts := c.outputOptions()
+	opts.Redact = c.redactOut
+
+	stream, err := NewWalletStream(file, c.format, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := StreamUntilSignal(file, stream, c.walletsAt); err != nil {
+		return fmt.Errorf("stopped after %d wallets: %w", stream.Count(), err)
+	}
+
+	return c.closeOutput(file)
+}
+
+// outputOptions returns the options of the text, table, CSV, JSON and
+// MessagePack output
+func (c *generateCommand) outputOptions() OutputOptions {
+	opts := OutputOptions{
+		ShowMasterKeys: c.showMasterKeys,
+		LegacyBip32:    c.legacyBip32,
+		Identicon:      c.showIdenticon,
+		SetHash:        c.showSetHash,
+		ScriptPubKey:   c.showScriptPubKey,
+		Witness:        c.showWitness,
+		Derivation:     c.showDerivation,
+		TapTweak:       c.tapTweakHash,
+		ChildXprv:      c.exportChildXprv,
+		Tree:           c.showTree,
+		PathArray:      c.showPathArray,
+		Passphrases:    len(c.passphraseList) > 1,
+		Change:         c.change != 0,
+		Separator:      c.walletSeparator,
+		Primary:        c.primary,
+		URI:            c.paymentRequest,
+		Redact:         c.redact,
+		SeedFormat:     c.seedFormat,
+		NumberOffset:   c.countFrom - 1,
+		Range:          c.addresses > 1 || len(c.stateFile) > 0 || len(c.indexList) > 0,
+	}
+
+	if c.format == "table" && !c.full {
+		opts.TableWidth = TableWidth()
+	}
+
+	return opts
+}
+
+// createOutput creates the -out file, named by -auto-name if set
+func (c *generateCommand) createOutput() (*os.File, error) {
+	fileName := c.out
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+
+	if c.autoName {
+		var err error
+		fileName, err = autoFileName(c.out, c.network, c.format, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		// Never overwrite the output of an earlier run
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+
+	file, err := os.OpenFile(fileName, flags, 0o666)
+	if err != nil {
+		return nil, fmt.Errorf("error creating file: %w", err)
+	}
+
+	return file, nil
+}
+
+// closeOutput closes the -out file and signs it if -sign-output is set
+func (c *generateCommand) closeOutput(file *os.File) error {
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing file: %w", err)
+	}
+
+	fmt.Println("Saved to:", file.Name())
+
+	if c.signOutput {
+		if err := WriteFileMAC(file.Name(), c.macKey); err != nil {
+			return err
+		}
+
+		fmt.Println("Saved HMAC to:", MACFileName(file.Name()))
 	}
+
+	return nil
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestBatchFailures checks a failed derivation leaves the rest of the batch
+// intact and the failures are counted against the wallets attempted, one per
+// passphrase, rather than -count
+func TestBatchFailures(t *testing.T) {
+	discardLogs(t)
+
+	c := &generateCommand{
+		count:          2,
+		seedFormat:     SeedFormatMnemonic,
+		params:         &chaincfg.MainNetParams,
+		mnemonicList:   []string{bip86Mnemonic, bip86Mnemonic},
+		passphraseList: []string{"", "TREZOR"},
+	}
+	c.derive = func(i int, wallet *Wallet) (Generated, error) {
+		if i == 1 {
+			return Generated{}, errors.New("injected failure")
+		}
+
+		return c.deriveWallet(i, wallet)
+	}
+
+	wallets, err := c.batch()
+	if len(wallets) != 2 {
+		t.Fatalf("%d wallets, expected the 2 of the first mnemonic: %v", len(wallets), err)
+	}
+
+	if err == nil || !strings.HasPrefix(err.Error(), "2 of 4 wallets failed") {
+		t.Errorf("error %v, expected 2 of 4 wallets failed", err)
+	}
+}
+
+// TestValidateStrength checks -count is at least 1 and the strengths of
+// -bits and -words
+func TestValidateStrength(t *testing.T) {
+	for _, v := range []struct {
+		name      string
+		count     int
+		bits      int
+		words     string
+		bitsSet   bool
+		strengths []int
+	}{
+		{"count 0", 0, 128, "", false, nil},
+		{"count -1", -1, 128, "", false, nil},
+		{"count 1", 1, 128, "", false, []int{128}},
+		{"bits", 1, 256, "", true, []int{256}},
+		{"invalid bits", 1, 130, "", true, nil},
+		{"words", 2, 128, "12,24", false, []int{128, 256}},
+		{"invalid words", 1, 128, "13", false, nil},
+		{"bits and words", 1, 256, "12", true, nil},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			strengths, err := validateStrength(v.count, v.bits, v.words, v.bitsSet)
+			if v.strengths == nil {
+				if err == nil {
+					t.Errorf("accepted with strengths %v", strengths)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(strengths, v.strengths) {
+				t.Errorf("strengths %v, expected %v", strengths, v.strengths)
+			}
+		})
+	}
+}
+
+// TestScanAlias checks scan dispatches to discover
+func TestScanAlias(t *testing.T) {
+	discardLogs(t)
+	t.Setenv(mnemonicEnv, "")
+
+	if err := run([]string{"scan"}); err == nil || err.Error() != "discover requires -mnemonic" {
+		t.Errorf("error %v, expected the -mnemonic check of discover", err)
+	}
+}
+
+// TestBuild386 cross-compiles the package and its tests for GOARCH=386, where
+// int is 32 bits and comparing an int flag against
+// hdkeychain.HardenedKeyStart overflows
+func TestBuild386(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the cross-compile in -short mode")
+	}
+
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	cmd := exec.Command(gobin, "vet", ".")
+	cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH=386")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("GOARCH=386 build failed: %v\n%s", err, out)
+	}
+}
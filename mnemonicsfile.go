@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// MnemonicLine is a mnemonic of a mnemonics file with its 1-based line number
+type MnemonicLine struct {
+	Line     int
+	Mnemonic string
+}
+
+// ReadMnemonicLines reads one mnemonic per line, skipping blank lines and #
+// comments. Duplicates are kept, see LoadMnemonics.
+func ReadMnemonicLines(path string) ([]MnemonicLine, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening mnemonics file: %w", err)
+	}
+	defer file.Close()
+
+	var mnemonics []MnemonicLine
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		mnemonics = append(mnemonics, MnemonicLine{Line: line, Mnemonic: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading mnemonics file: %w", err)
+	}
+
+	if len(mnemonics) == 0 {
+		return nil, fmt.Errorf("mnemonics file has no mnemonics")
+	}
+
+	return mnemonics, nil
+}
+
+// LoadMnemonics reads one mnemonic per line for a bulk restore, skipping blank
+// lines and # comments. Mnemonics repeating an earlier line in normalized form
+// are an error listing their line numbers, or with dedupe are dropped with a
+// warning.
+func LoadMnemonics(path string, dedupe bool) ([]string, error) {
+	lines, err := ReadMnemonicLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mnemonics []string
+	var duplicates []string
+	firstLine := make(map[string]int)
+
+	for _, line := range lines {
+		normalized := normalizeMnemonic(line.Mnemonic)
+		if first, ok := firstLine[normalized]; ok {
+			if dedupe {
+				slog.Warn("skipping duplicate mnemonic", "line", line.Line, "first_line", first)
+			}
+			duplicates = append(duplicates, fmt.Sprintf("line %d repeats line %d", line.Line, first))
+			continue
+		}
+		firstLine[normalized] = line.Line
+
+		mnemonics = append(mnemonics, line.Mnemonic)
+	}
+
+	if len(duplicates) > 0 && !dedupe {
+		return nil, fmt.Errorf("mnemonics file has %d duplicates (%s), use -dedupe to skip them", len(duplicates), strings.Join(duplicates, ", "))
+	}
+
+	return mnemonics, nil
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// writeMsgpack writes each wallet as one MessagePack map, back to back, so
+// consumers can decode the stream a wallet at a time. The maps hold the keys
+// and values of the JSON output: the records go through encoding/json, which
+// keeps the two schemas from drifting apart.
+func writeMsgpack(w io.Writer, wallets []Generated, opts OutputOptions) error {
+	records, err := walletRecords(wallets, opts)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	for _, record := range records {
+		if err := writeMsgpackRecord(writer, record); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing MessagePack: %w", err)
+	}
+
+	return nil
+}
+
+// writeMsgpackRecord encodes a single wallet record of the stream
+func writeMsgpackRecord(w *bufio.Writer, record walletJSON) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	return encodeMsgpack(w, value)
+}
+
+// encodeMsgpack encodes a value decoded from JSON with UseNumber. Map keys are
+// sorted so the same wallet always encodes to the same bytes.
+func encodeMsgpack(w *bufio.Writer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		w.WriteByte(0xc0)
+	case bool:
+		if v {
+			w.WriteByte(0xc3)
+		} else {
+			w.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			writeMsgpackInt(w, n)
+			break
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid number %s", v)
+		}
+		w.WriteByte(0xcb)
+		binary.Write(w, binary.BigEndian, math.Float64bits(f))
+	case string:
+		writeMsgpackHeader(w, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		w.WriteString(v)
+	case []any:
+		writeMsgpackHeader(w, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := encodeMsgpack(w, item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeMsgpackHeader(w, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			if err := encodeMsgpack(w, key); err != nil {
+				return err
+			}
+			if err := encodeMsgpack(w, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot encode %T as MessagePack", value)
+	}
+
+	return nil
+}
+
+// writeMsgpackInt writes n in the smallest integer encoding
+func writeMsgpackInt(w *bufio.Writer, n int64) {
+	switch {
+	case n >= 0 && n < 128:
+		w.WriteByte(byte(n))
+	case n < 0 && n >= -32:
+		w.WriteByte(byte(int8(n)))
+	case n >= 0 && n <= math.MaxUint8:
+		w.Write([]byte{0xcc, byte(n)})
+	case n >= 0 && n <= math.MaxUint16:
+		w.WriteByte(0xcd)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	case n >= 0 && n <= math.MaxUint32:
+		w.WriteByte(0xce)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	default:
+		w.WriteByte(0xd3)
+		binary.Write(w, binary.BigEndian, n)
+	}
+}
+
+// writeMsgpackHeader writes the type and length of a string, array or map:
+// the fix format below fixLimit, else the 8 bit (if the type has one), 16 or
+// 32 bit length format
+func writeMsgpackHeader(w *bufio.Writer, n int, fix byte, fixLimit int, len8 byte, len16 byte, len32 byte) {
+	switch {
+	case n < fixLimit:
+		w.WriteByte(fix | byte(n))
+	case len8 != 0 && n <= math.MaxUint8:
+		w.Write([]byte{len8, byte(n)})
+	case n <= math.MaxUint16:
+		w.WriteByte(len16)
+		binary.Write(w, binary.BigEndian, uint16(n))
+	default:
+		w.WriteByte(len32)
+		binary.Write(w, binary.BigEndian, uint32(n))
+	}
+}
+
+// decodeMsgpack decodes the next value of a stream written by writeMsgpack,
+// returning io.EOF at its clean end. Only the formats writeMsgpack emits are
+// supported; integers decode as int64 and floats as float64.
+func decodeMsgpack(r *bufio.Reader) (any, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b < 0x80:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return decodeMsgpackMap(r, int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return decodeMsgpackArray(r, int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		return decodeMsgpackString(r, int(b&0x1f))
+	}
+
+	// The fixed size formats are followed by their big-endian value or length
+	sizes := map[byte]int{0xcc: 1, 0xcd: 2, 0xce: 4, 0xd3: 8, 0xcb: 8, 0xd9: 1, 0xda: 2, 0xdb: 4, 0xdc: 2, 0xdd: 4, 0xde: 2, 0xdf: 4}
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	}
+
+	size, ok := sizes[b]
+	if !ok {
+		return nil, fmt.Errorf("unsupported MessagePack format 0x%02x", b)
+	}
+
+	buf := make([]byte, 8)
+	if _, err := io.ReadFull(r, buf[8-size:]); err != nil {
+		return nil, fmt.Errorf("truncated MessagePack value: %w", err)
+	}
+	n := binary.BigEndian.Uint64(buf)
+
+	switch b {
+	case 0xcc, 0xcd, 0xce, 0xd3:
+		return int64(n), nil
+	case 0xcb:
+		return math.Float64frombits(n), nil
+	case 0xd9, 0xda, 0xdb:
+		return decodeMsgpackString(r, int(n))
+	case 0xdc, 0xdd:
+		return decodeMsgpackArray(r, int(n))
+	default:
+		return decodeMsgpackMap(r, int(n))
+	}
+}
+
+func decodeMsgpackString(r *bufio.Reader, n int) (any, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, fmt.Errorf("truncated MessagePack string: %w", err)
+	}
+
+	return string(buf), nil
+}
+
+func decodeMsgpackArray(r *bufio.Reader, n int) (any, error) {
+	items := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		item, err := decodeMsgpackNested(r)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func decodeMsgpackMap(r *bufio.Reader, n int) (any, error) {
+	entries := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		key, err := decodeMsgpackNested(r)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("MessagePack map key %v is not a string", key)
+		}
+
+		entries[name], err = decodeMsgpackNested(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+// decodeMsgpackNested decodes a value inside an array or map, where the end
+// of the stream means it was truncated
+func decodeMsgpackNested(r *bufio.Reader) (any, error) {
+	value, err := decodeMsgpack(r)
+	if err == io.EOF {
+		return nil, fmt.Errorf("truncated MessagePack value: %w", io.ErrUnexpectedEOF)
+	}
+
+	return value, err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+// TestMsgpack decodes the MessagePack stream of two wallets and compares it
+// with their JSON output
+func TestMsgpack(t *testing.T) {
+	wallet := testWallet(t)
+
+	set, err := wallet.DeriveAll(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated := Generated{
+		P2pkhAddress:      set.P2pkhAddress,
+		P2wpkhP2shAddress: set.P2wpkhP2shAddress,
+		P2wpkhAddress:     set.P2wpkhAddress,
+		TaprootAddress:    set.TaprootAddress,
+		Mnemonic:          wallet.Mnemonic,
+		Addresses:         []AddressSet{set},
+	}
+	wallets := []Generated{generated, generated}
+	opts := OutputOptions{Range: true, ScriptPubKey: true, Witness: true}
+
+	var stream bytes.Buffer
+	if err := WriteWallets(&stream, "msgpack", wallets, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []any
+	reader := bufio.NewReader(&stream)
+	for {
+		value, err := decodeMsgpack(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error decoding MessagePack stream: %v", err)
+		}
+
+		decoded = append(decoded, value)
+	}
+
+	var out bytes.Buffer
+	if err := WriteWallets(&out, "json", wallets, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []any
+	if err := json.Unmarshal(out.Bytes(), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both sides marshal with sorted keys and integral numbers
+	got, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Fatalf("MessagePack stream decodes to %s, expected %s", got, want)
+	}
+}
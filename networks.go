@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"unicode"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// allowMainnetEnv guards mainnet use in scripted environments: when it is set
+// to anything but 1, every subcommand requires -confirm-mainnet for mainnet
+const allowMainnetEnv = "BTC_WALLET_ALLOW_MAINNET"
+
+// CheckMainnetAllowed returns an error if mainnet was selected while the
+// guard environment variable forbids it and it was not explicitly confirmed
+func CheckMainnetAllowed(params *chaincfg.Params, confirmed bool) error {
+	if params.Net != chaincfg.MainNetParams.Net || confirmed {
+		return nil
+	}
+
+	value, ok := os.LookupEnv(allowMainnetEnv)
+	if !ok || value == "1" {
+		return nil
+	}
+
+	return fmt.Errorf("mainnet is disabled by %s=%q, set it to 1 or pass -confirm-mainnet", allowMainnetEnv, value)
+}
+
+// networks maps the -network flag values to their chain parameters. The
+// mainnet networks are added by networks_mainnet.go unless the build has the
+// testnetonly tag.
+var networks = map[string]*chaincfg.Params{
+	"testnet": &chaincfg.TestNet3Params,
+	"regtest": &chaincfg.RegressionNetParams,
+	"signet":  &chaincfg.SigNetParams,
+}
+
+// networkNames lists the networks in preference order, testnet before signet
+// since both share the tb prefix
+var networkNames = []string{"testnet", "regtest", "signet"}
+
+// NetworkParams returns the chain parameters for the given network name
+func NetworkParams(name string) (*chaincfg.Params, error) {
+	params, ok := networks[name]
+	if !ok {
+		if slices.Contains(excludedNetworks, name) {
+			return nil, fmt.Errorf("network %q is excluded from this testnetonly build", name)
+		}
+
+		return nil, fmt.Errorf("unknown network %q", name)
+	}
+
+	return params, nil
+}
+
+// customSignets caches the parameters of SignetWithHRP by prefix, chaincfg
+// accepts every network only once
+var customSignets = make(map[string]*chaincfg.Params)
+
+// SignetWithHRP returns signet parameters with a custom bech32 prefix for
+// private signets, registered so their addresses decode. The network keeps
+// the signet name and key versions but gets a message start derived from the
+// prefix, which only serves to register it.
+func SignetWithHRP(hrp string) (*chaincfg.Params, error) {
+	if hrp == chaincfg.SigNetParams.Bech32HRPSegwit {
+		return &chaincfg.SigNetParams, nil
+	}
+
+	if params, ok := customSignets[hrp]; ok {
+		return params, nil
+	}
+
+	if len(hrp) < 1 || len(hrp) > 83 {
+		return nil, fmt.Errorf("invalid HRP %q: must be 1 to 83 characters", hrp)
+	}
+
+	for _, c := range hrp {
+		if c < 33 || c > 126 || unicode.IsUpper(c) {
+			return nil, fmt.Errorf("invalid HRP %q: must be lowercase printable ASCII", hrp)
+		}
+	}
+
+	for name, params := range networks {
+		if params.Bech32HRPSegwit == hrp {
+			return nil, fmt.Errorf("invalid HRP %q: already used by %s", hrp, name)
+		}
+	}
+
+	hash := sha256.Sum256([]byte("signet hrp " + hrp))
+
+	params := chaincfg.SigNetParams
+	params.Net = wire.BitcoinNet(binary.LittleEndian.Uint32(hash[:4]))
+	params.Bech32HRPSegwit = hrp
+	if err := chaincfg.Register(&params); err != nil {
+		return nil, fmt.Errorf("error registering signet with HRP %q: %w", hrp, err)
+	}
+
+	customSignets[hrp] = &params
+	return &params, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// LiquidTestNetParams defines the parameters of the Liquid testnet for
+// unblinded addresses: the tex bech32 prefix and the Elements base58
+// prefixes. Extended keys serialize like Bitcoin testnet keys.
+var LiquidTestNetParams = func() chaincfg.Params {
+	params := chaincfg.TestNet3Params
+	params.Name = "liquidtestnet"
+	params.Net = wire.BitcoinNet(0x62dd0e41)
+	params.Bech32HRPSegwit = "tex"
+	params.PubKeyHashAddrID = 0x24 // starts with F
+	params.ScriptHashAddrID = 0x13 // starts with 8 or 9
+	return params
+}()
+
+// LiquidWarning is logged whenever addresses are derived for Liquid
+const LiquidWarning = "Liquid support is limited to unblinded addresses, which reveal amounts and assets on chain. " +
+	"Confidential addresses need a blinding key and are not supported. The P2TR addresses use the Bitcoin Taproot tweak, " +
+	"which Elements computes differently, so do not fund them."
+
+// IsLiquid reports whether the chain parameters are those of a Liquid network
+func IsLiquid(params *chaincfg.Params) bool {
+	return params.Name == "liquid" || params.Name == "liquidtestnet"
+}
+
+func init() {
+	if err := chaincfg.Register(&LiquidTestNetParams); err != nil {
+		panic(fmt.Sprintf("failed to register liquidtestnet network: %v", err))
+	}
+
+	networks["liquidtestnet"] = &LiquidTestNetParams
+	networkNames = append(networkNames, "liquidtestnet")
+}
@@ -0,0 +1,69 @@
+//go:build !testnetonly
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// defaultNetwork is the -network default
+const defaultNetwork = "mainnet"
+
+// excludedNetworks is empty, builds with the testnetonly tag exclude the
+// mainnet networks, see networks_testnetonly.go
+var excludedNetworks []string
+
+// LitecoinMainNetParams defines the network parameters for the Litecoin main
+// network. Only the fields used for key and address serialization differ from
+// Bitcoin; extended keys serialize with the Ltpv/Ltub prefixes.
+var LitecoinMainNetParams = func() chaincfg.Params {
+	params := chaincfg.MainNetParams
+	params.Name = "litecoin"
+	params.Net = wire.BitcoinNet(0xdbb6c0fb)
+	params.Bech32HRPSegwit = "ltc"
+	params.PubKeyHashAddrID = 0x30 // starts with L
+	params.ScriptHashAddrID = 0x32 // starts with M
+	params.PrivateKeyID = 0xb0
+	params.HDPrivateKeyID = [4]byte{0x01, 0x9d, 0x9c, 0xfe} // starts with Ltpv
+	params.HDPublicKeyID = [4]byte{0x01, 0x9d, 0xa4, 0x62}  // starts with Ltub
+	params.HDCoinType = 2
+	return params
+}()
+
+// LiquidMainNetParams defines the parameters of the Liquid network for
+// unblinded addresses, see LiquidTestNetParams. Addresses derive under the
+// SLIP-44 coin type of Liquid Bitcoin.
+var LiquidMainNetParams = func() chaincfg.Params {
+	params := chaincfg.MainNetParams
+	params.Name = "liquid"
+	// Liquid shares its message start with regtest, which chaincfg.Register
+	// rejects, so a magic of its own is used as it is never sent on the wire
+	params.Net = wire.BitcoinNet(0x4c697164)
+	params.Bech32HRPSegwit = "ex"
+	params.PubKeyHashAddrID = 0x39 // starts with Q
+	params.ScriptHashAddrID = 0x27 // starts with G or H
+	params.HDCoinType = 1776
+	return params
+}()
+
+func init() {
+	// Register the Litecoin params so that extended keys can be neutered and
+	// addresses decoded for the network.
+	if err := chaincfg.Register(&LitecoinMainNetParams); err != nil {
+		panic(fmt.Sprintf("failed to register litecoin network: %v", err))
+	}
+
+	if err := chaincfg.Register(&LiquidMainNetParams); err != nil {
+		panic(fmt.Sprintf("failed to register liquid network: %v", err))
+	}
+
+	// Mainnet goes first so it wins the shared address prefixes, the other
+	// chains last as they are the least likely match
+	networks["mainnet"] = &chaincfg.MainNetParams
+	networks["litecoin"] = &LitecoinMainNetParams
+	networks["liquid"] = &LiquidMainNetParams
+	networkNames = append(append([]string{"mainnet"}, networkNames...), "litecoin", "liquid")
+}
@@ -0,0 +1,108 @@
+//go:build !testnetonly
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// litecoinBIP84Address is the first BIP-84 receive address of bip86Mnemonic on
+// Litecoin, m/84'/2'/0'/0/0
+const litecoinBIP84Address = "ltc1qjmxnz78nmc8nq77wuxh25n2es7rzm5c2rkk4wh"
+
+// TestLitecoin checks the Litecoin account keys serialize with the Ltpv and
+// Ltub version bytes and the addresses derive under coin type 2
+func TestLitecoin(t *testing.T) {
+	wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", &LitecoinMainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := wallet.AccountKey(44)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, err := account.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []struct {
+		key     string
+		prefix  string
+		version []byte
+	}{
+		{account.String(), "Ltpv", []byte{0x01, 0x9d, 0x9c, 0xfe}},
+		{public.String(), "Ltub", []byte{0x01, 0x9d, 0xa4, 0x62}},
+	} {
+		if !strings.HasPrefix(v.key, v.prefix) {
+			t.Errorf("account key %s, expected the %s prefix", v.key, v.prefix)
+		}
+		if decoded := base58.Decode(v.key); !bytes.HasPrefix(decoded, v.version) {
+			t.Errorf("account key %s has version %x, expected %x", v.key, decoded[:4], v.version)
+		}
+	}
+
+	if path := wallet.DerivationPath(AddressP2WPKH.Purpose(), 0, 0); path != "m/84'/2'/0'/0/0" {
+		t.Errorf("P2WPKH path %s, expected coin type 2", path)
+	}
+
+	address, err := wallet.DeriveAddress(AddressP2WPKH, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded := address.EncodeAddress(); encoded != litecoinBIP84Address {
+		t.Errorf("P2WPKH address %s, expected %s", encoded, litecoinBIP84Address)
+	}
+}
+
+// TestVerifyMainnetGuard checks the address search of verify skips mainnet
+// while it is guarded, and the -xpub check refuses a mainnet xpub
+func TestVerifyMainnetGuard(t *testing.T) {
+	t.Setenv(allowMainnetEnv, "0")
+
+	testnet, err := NewWalletFromMnemonic(bip86Mnemonic, "", &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := testnet.DeriveAddress(AddressP2WPKH, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match, err := FindAddressNetwork(bip86Mnemonic, "", address.EncodeAddress(), 5, false)
+	if err != nil || match.Network != "testnet" {
+		t.Fatalf("testnet address not found while mainnet is guarded: %v", err)
+	}
+
+	mainnet := bip86Vectors[0].address
+	if _, err := FindAddressNetwork(bip86Mnemonic, "", mainnet, 5, false); err == nil || !strings.Contains(err.Error(), allowMainnetEnv) {
+		t.Errorf("error %v, expected the mainnet guard", err)
+	}
+	if _, err := FindAddressNetwork(bip86Mnemonic, "", mainnet, 5, true); err != nil {
+		t.Errorf("confirmed mainnet address not found: %v", err)
+	}
+
+	account, err := testWallet(t).AccountKey(84)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xpub, err := account.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"-mnemonic", bip86Mnemonic, "-xpub", xpub.String()}
+	if err := runVerify(args); err == nil || !strings.Contains(err.Error(), allowMainnetEnv) {
+		t.Errorf("error %v, expected the mainnet guard", err)
+	}
+	if err := runVerify(append(args, "-confirm-mainnet")); err != nil {
+		t.Errorf("confirmed mainnet xpub rejected: %v", err)
+	}
+}
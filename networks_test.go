@@ -0,0 +1,176 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestExcludedNetworks checks that the networks left out of a testnetonly
+// build cannot be selected
+func TestExcludedNetworks(t *testing.T) {
+	for _, name := range excludedNetworks {
+		if _, err := NetworkParams(name); err == nil {
+			t.Fatalf("network %s is excluded from this build but selectable", name)
+		}
+	}
+}
+
+// TestLiquid checks the unblinded Liquid P2WPKH addresses use the Liquid
+// bech32 prefixes. The liquid network is left out of testnetonly builds.
+func TestLiquid(t *testing.T) {
+	for name, prefix := range map[string]string{"liquid": "ex1q", "liquidtestnet": "tex1q"} {
+		params, err := NetworkParams(name)
+		if err != nil {
+			if name == "liquid" {
+				continue
+			}
+			t.Fatal(err)
+		}
+
+		wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		address, err := wallet.DeriveAddress(AddressP2WPKH, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if encoded := address.EncodeAddress(); !strings.HasPrefix(encoded, prefix) {
+			t.Fatalf("%s P2WPKH address %s, expected the %s prefix", name, encoded, prefix)
+		}
+	}
+}
+
+// TestRegtest derives every address type on regtest and checks the bcrt
+// bech32 prefix, the regtest version bytes of the P2PKH and nested BIP-49
+// addresses and that each address decodes for regtest only
+func TestRegtest(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+
+	wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefixes := map[AddressType][]string{
+		AddressP2PKH:        {"m", "n"},
+		AddressP2WPKHInP2SH: {"2"},
+		AddressP2WPKH:       {"bcrt1q"},
+		AddressTaproot:      {"bcrt1p"},
+	}
+
+	for _, typ := range AddressTypes {
+		address, err := wallet.DeriveAddress(typ, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encoded := address.EncodeAddress()
+		if !slices.ContainsFunc(prefixes[typ], func(prefix string) bool { return strings.HasPrefix(encoded, prefix) }) {
+			t.Fatalf("regtest %s address %s, expected a prefix in %v", typ.Name(), encoded, prefixes[typ])
+		}
+
+		decoded, err := btcutil.DecodeAddress(encoded, params)
+		if err != nil {
+			t.Fatalf("error decoding regtest %s address %s: %v", typ.Name(), encoded, err)
+		}
+
+		if !decoded.IsForNet(params) || decoded.IsForNet(&chaincfg.MainNetParams) {
+			t.Fatalf("regtest %s address %s is not for regtest only", typ.Name(), encoded)
+		}
+	}
+
+	// Regtest shares its base58 version bytes with testnet, so IsForNet alone
+	// cannot tell them apart; check the bytes against the params directly
+	for typ, version := range map[AddressType]byte{AddressP2PKH: params.PubKeyHashAddrID, AddressP2WPKHInP2SH: params.ScriptHashAddrID} {
+		address, err := wallet.DeriveAddress(typ, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, decoded, err := base58.CheckDecode(address.EncodeAddress())
+		if err != nil {
+			t.Fatalf("error decoding regtest %s address: %v", typ.Name(), err)
+		}
+
+		if decoded != version {
+			t.Fatalf("regtest %s address version 0x%02x, expected 0x%02x", typ.Name(), decoded, version)
+		}
+	}
+}
+
+// TestSignetHRP derives the native SegWit and Taproot addresses of a signet
+// with a custom prefix, checks they decode for it only and that uppercase or
+// taken prefixes are rejected
+func TestSignetHRP(t *testing.T) {
+	params, err := SignetWithHRP("sbtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again, err := SignetWithHRP("sbtest"); err != nil || again != params {
+		t.Fatalf("custom signet not reused: %v", err)
+	}
+
+	wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range []AddressType{AddressP2WPKH, AddressTaproot} {
+		address, err := wallet.DeriveAddress(typ, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := CheckAddressRoundTrip(typ, address, params); err != nil {
+			t.Fatal(err)
+		}
+
+		encoded := address.EncodeAddress()
+		if !strings.HasPrefix(encoded, "sbtest1") {
+			t.Fatalf("custom signet %s address %s, expected the sbtest1 prefix", typ.Name(), encoded)
+		}
+
+		if check := CheckAddress(encoded, &chaincfg.SigNetParams); check.Status == AddressValid {
+			t.Fatalf("custom signet %s address %s is valid on signet", typ.Name(), encoded)
+		}
+	}
+
+	for _, hrp := range []string{"SB", "", "bcrt"} {
+		if _, err := SignetWithHRP(hrp); err == nil {
+			t.Fatalf("invalid HRP %q accepted", hrp)
+		}
+	}
+}
+
+// TestMainnetGuard checks BTC_WALLET_ALLOW_MAINNET blocks mainnet unless it is
+// 1 or the use is confirmed, and never blocks the test networks
+func TestMainnetGuard(t *testing.T) {
+	for _, v := range []struct {
+		name      string
+		params    *chaincfg.Params
+		env       string
+		confirmed bool
+		allowed   bool
+	}{
+		{"allowed", &chaincfg.MainNetParams, "1", false, true},
+		{"guarded", &chaincfg.MainNetParams, "0", false, false},
+		{"confirmed", &chaincfg.MainNetParams, "0", true, true},
+		{"testnet", &chaincfg.TestNet3Params, "0", false, true},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			t.Setenv(allowMainnetEnv, v.env)
+			if err := CheckMainnetAllowed(v.params, v.confirmed); (err == nil) != v.allowed {
+				t.Errorf("error %v, expected allowed %t", err, v.allowed)
+			}
+		})
+	}
+}
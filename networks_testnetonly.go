@@ -0,0 +1,17 @@
+//go:build testnetonly
+
+package main
+
+// Builds with the testnetonly tag leave out the mainnet chain parameters, so
+// that no flag or misconfiguration can make them produce mainnet keys:
+//
+//	go build -tags testnetonly
+//
+// -network mainnet, litecoin and liquid are rejected and testnet is the
+// default.
+
+// defaultNetwork is the -network default
+const defaultNetwork = "testnet"
+
+// excludedNetworks are the networks left out of this build
+var excludedNetworks = []string{"mainnet", "litecoin", "liquid"}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// notifyAddress is a derived address of the -notify-url payload
+type notifyAddress struct {
+	Wallet  int    `json:"wallet"`
+	Type    string `json:"type"`
+	Change  bool   `json:"change"`
+	Index   uint32 `json:"index"`
+	Path    string `json:"path"`
+	Address string `json:"address"`
+}
+
+// notifyPayload is the JSON body posted to -notify-url. It only ever holds
+// addresses and their paths, never mnemonics or keys.
+type notifyPayload struct {
+	Network   string          `json:"network"`
+	Addresses []notifyAddress `json:"addresses"`
+}
+
+// Notifier posts derived addresses to a monitoring webhook, retrying failed
+// requests with exponential backoff
+type Notifier struct {
+	URL      string
+	Client   *http.Client
+	Attempts int
+
+	// Backoff is the delay before the first retry, doubled for each next one
+	Backoff time.Duration
+}
+
+// NewNotifier returns a notifier for the http or https URL, making up to 4
+// attempts starting with a 1 second backoff
+func NewNotifier(rawURL string) (*Notifier, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || len(parsed.Host) == 0 {
+		return nil, fmt.Errorf("invalid notify URL %q: must be an http or https URL", rawURL)
+	}
+
+	return &Notifier{
+		URL:      rawURL,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+		Attempts: 4,
+		Backoff:  time.Second,
+	}, nil
+}
+
+// NotifyAddresses posts the receive or change addresses of every wallet, as
+// derived for the output, to the webhook
+func (n *Notifier) NotifyAddresses(wallets []Generated, params *chaincfg.Params) error {
+	payload := notifyPayload{Network: params.Name, Addresses: []notifyAddress{}}
+	for i, wallet := range wallets {
+		for _, set := range wallet.AddressSets() {
+			for _, t := range AddressTypes {
+				payload.Addresses = append(payload.Addresses, notifyAddress{
+					Wallet:  i + 1,
+					Type:    t.Name(),
+					Change:  set.Change != 0,
+					Index:   set.Index,
+					Path:    fmt.Sprintf("m/%d'/%d'/0'/%d/%d", t.Purpose(), params.HDCoinType, set.Change, set.Index),
+					Address: set.Address(t).EncodeAddress(),
+				})
+			}
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding notification: %w", err)
+	}
+
+	return n.post(body)
+}
+
+// post sends the body, retrying connection errors, 429 and 5xx responses.
+// Other responses are final, as repeating the request cannot change them.
+func (n *Notifier) post(body []byte) error {
+	var err error
+	delay := n.Backoff
+	for attempt := 1; attempt <= n.Attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		var retry bool
+		retry, err = n.postOnce(body)
+		if err == nil || !retry {
+			return err
+		}
+
+		slog.Warn("notification failed", "attempt", attempt, "attempts", n.Attempts, "error", err)
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", n.Attempts, err)
+}
+
+func (n *Notifier) postOnce(body []byte) (bool, error) {
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, fmt.Errorf("error posting notification: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("notify URL returned %s", resp.Status)
+}
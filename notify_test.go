@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestNotify posts to a local webhook failing the first request and checks
+// the retried payload holds the addresses but no secret material
+func TestNotify(t *testing.T) {
+	wallet := testWallet(t)
+
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.Backoff = time.Millisecond
+
+	set, err := wallet.DeriveAll(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated := Generated{
+		P2pkhAddress:      set.P2pkhAddress,
+		P2wpkhP2shAddress: set.P2wpkhP2shAddress,
+		P2wpkhAddress:     set.P2wpkhAddress,
+		TaprootAddress:    set.TaprootAddress,
+		Mnemonic:          wallet.Mnemonic,
+		MasterXprv:        wallet.MasterKey.String(),
+	}
+
+	// The retry warning is expected
+	discardLogs(t)
+
+	if err := notifier.NotifyAddresses([]Generated{generated}, &chaincfg.MainNetParams); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("webhook received %d requests, expected a failure and a retry", len(bodies))
+	}
+
+	var payload notifyPayload
+	if err := json.Unmarshal(bodies[1], &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payload.Addresses) != len(AddressTypes) || payload.Addresses[2].Address != bip84Address {
+		t.Fatalf("webhook received %d addresses, expected %d including %s", len(payload.Addresses), len(AddressTypes), bip84Address)
+	}
+
+	for _, secret := range []string{"abandon", "xprv"} {
+		if bytes.Contains(bodies[1], []byte(secret)) {
+			t.Fatalf("webhook payload contains %s", secret)
+		}
+	}
+
+	for _, invalid := range []string{"", "ftp://example.com", "example.com/hook"} {
+		if _, err := NewNotifier(invalid); err == nil {
+			t.Fatalf("notify URL %q was accepted", invalid)
+		}
+	}
+}
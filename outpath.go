@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ValidateOutputPath checks that -out can be written before any wallet is
+// generated, so a bad path fails fast with an actionable error instead of
+// after the derivation work. With autoName the path is the directory the file
+// is created in.
+func ValidateOutputPath(path string, autoName bool) error {
+	info, err := os.Stat(path)
+
+	if autoName {
+		if err != nil {
+			return fmt.Errorf("-out directory %s: %w", path, describePathError(err))
+		}
+
+		if !info.IsDir() {
+			return fmt.Errorf("-auto-name requires -out to be a directory, %s is a file", path)
+		}
+
+		return checkWritableDir(path)
+	}
+
+	switch {
+	case err == nil && info.IsDir():
+		return fmt.Errorf("-out %s is a directory, name a file in it or add -auto-name", path)
+	case err == nil:
+		// Open without truncating, the existing file is only replaced once
+		// the wallets are generated
+		file, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("-out file %s is not writable: %w", path, describePathError(err))
+		}
+
+		return file.Close()
+	case errors.Is(err, fs.ErrNotExist):
+		dir := filepath.Dir(path)
+
+		parent, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("-out parent directory %s: %w", dir, describePathError(err))
+		}
+
+		if !parent.IsDir() {
+			return fmt.Errorf("-out parent %s is not a directory", dir)
+		}
+
+		return checkWritableDir(dir)
+	default:
+		return fmt.Errorf("-out %s: %w", path, describePathError(err))
+	}
+}
+
+// checkWritableDir creates and removes a temporary file in dir, which covers
+// permissions, read-only mounts and ACLs alike
+func checkWritableDir(dir string) error {
+	file, err := os.CreateTemp(dir, ".btc-wallet-write-check-*")
+	if err != nil {
+		return fmt.Errorf("directory %s is not writable: %w", dir, describePathError(err))
+	}
+
+	name := file.Name()
+	file.Close()
+
+	return os.Remove(name)
+}
+
+// describePathError reduces a path error to its cause with a hint, the path
+// is already part of the caller's message
+func describePathError(err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("does not exist, create it first")
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("permission denied, check the owner and mode")
+	default:
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return pathErr.Err
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOutputPath checks the -out validation in a temporary directory: the
+// directory itself, a file in a missing directory and, unless running as root
+// which bypasses permissions, a read-only directory are rejected
+func TestOutputPath(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := ValidateOutputPath(filepath.Join(dir, "wallets.csv"), false); err != nil {
+		t.Fatalf("valid -out rejected: %v", err)
+	}
+
+	if err := ValidateOutputPath(dir, true); err != nil {
+		t.Fatalf("valid -auto-name directory rejected: %v", err)
+	}
+
+	rejected := []string{dir, filepath.Join(dir, "missing", "wallets.csv")}
+
+	if os.Geteuid() != 0 {
+		readOnly := filepath.Join(dir, "read-only")
+		if err := os.Mkdir(readOnly, 0o500); err != nil {
+			t.Fatalf("error creating read-only directory: %v", err)
+		}
+
+		rejected = append(rejected, filepath.Join(readOnly, "wallets.csv"))
+	}
+
+	for _, path := range rejected {
+		if err := ValidateOutputPath(path, false); err == nil {
+			t.Fatalf("-out %s was accepted", path)
+		}
+	}
+}
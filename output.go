@@ -0,0 +1,680 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// OutputOptions selects the optional fields included in the output
+type OutputOptions struct {
+	ShowMasterKeys bool
+	LegacyBip32    bool
+	Identicon      bool
+	SetHash        bool
+	Range          bool
+	ScriptPubKey   bool
+	Witness        bool
+	Derivation     bool
+	TapTweak       bool
+	ChildXprv      bool
+	Tree           bool
+	PathArray      bool
+
+	// Passphrases labels each wallet with the index of its passphrase, set
+	// when several -passphrase flags derive hidden wallets
+	Passphrases bool
+
+	// Change labels the addresses with their chain, set when the change
+	// chain was derived
+	Change bool
+
+	// Separator is written between wallets in text output
+	Separator string
+
+	// Primary, when set, reduces text output to the bare addresses of this
+	// type, one per line, for capture by scripts
+	Primary *AddressType
+
+	// TableWidth is the width -format table truncates the mnemonic column
+	// to, 0 leaves it in full
+	TableWidth int
+
+	// URI, when set, wraps the addresses of text, table and CSV output in BIP-21
+	// URIs with its amount and label
+	URI *PaymentRequest
+
+	// Redact masks the mnemonics, see RedactMnemonic
+	Redact bool
+
+	// NumberOffset is added to the 1-based wallet numbers of CSV, table and
+	// structured output, -count-from uses it to continue an earlier run
+	NumberOffset int
+
+	// SeedFormat selects the secret written per wallet instead of the
+	// mnemonic, see Wallet.SeedSecret
+	SeedFormat string
+}
+
+// RedactMnemonic masks all but the first and last word of the mnemonic. The
+// mask has a fixed width so it does not reveal the word count.
+func RedactMnemonic(mnemonic string) string {
+	words := strings.Fields(mnemonic)
+	if len(words) < 2 {
+		return "********"
+	}
+
+	return words[0] + " ******** " + words[len(words)-1]
+}
+
+// formatMnemonic returns the mnemonic, masked if requested
+func (o OutputOptions) formatMnemonic(mnemonic string) string {
+	if o.Redact {
+		return RedactMnemonic(mnemonic)
+	}
+
+	return mnemonic
+}
+
+// secretLabel names the secret written per wallet
+func (o OutputOptions) secretLabel() string {
+	switch o.SeedFormat {
+	case SeedFormatHex:
+		return "Seed"
+	case SeedFormatEntropyHex:
+		return "Entropy"
+	case SeedFormatAezeed:
+		return "Aezeed"
+	default:
+		return "Mnemonic"
+	}
+}
+
+// formatSecret returns the secret of the wallet selected by SeedFormat, the
+// mnemonic or aezeed masked if requested
+func (o OutputOptions) formatSecret(wallet Generated) string {
+	if o.SeedFormat == SeedFormatMnemonic || o.SeedFormat == SeedFormatAezeed || len(o.SeedFormat) == 0 {
+		return o.formatMnemonic(wallet.Mnemonic)
+	}
+
+	return wallet.Secret
+}
+
+// formatAddress returns the address, or its BIP-21 URI if requested
+func (o OutputOptions) formatAddress(address btcutil.Address) string {
+	if o.URI != nil {
+		return o.URI.URI(address)
+	}
+
+	return address.EncodeAddress()
+}
+
+// WriteWallets writes the generated wallets to w in the given format
+func WriteWallets(w io.Writer, format string, wallets []Generated, opts OutputOptions) error {
+	switch format {
+	case "text":
+		return writeText(w, wallets, opts)
+	case "csv":
+		return writeCSV(w, wallets, opts)
+	case "json":
+		return writeJSON(w, wallets, opts)
+	case "table":
+		return writeTable(w, wallets, opts)
+	case "msgpack":
+		return writeMsgpack(w, wallets, opts)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+func writeText(w io.Writer, wallets []Generated, opts OutputOptions) error {
+	if opts.Primary != nil {
+		for _, wallet := range wallets {
+			for _, set := range wallet.AddressSets() {
+				fmt.Fprintln(w, opts.formatAddress(set.Address(*opts.Primary)))
+			}
+		}
+
+		return nil
+	}
+
+	for i, wallet := range wallets {
+		fmt.Fprintf(w, "%s: %s\n", opts.secretLabel(), opts.formatSecret(wallet))
+
+		if opts.Passphrases {
+			fmt.Fprintf(w, "Passphrase: #%d\n", wallet.PassphraseIndex)
+		}
+
+		if opts.Identicon {
+			fmt.Fprintln(w, "Identicon:", wallet.Identicon)
+		}
+
+		if opts.SetHash {
+			fmt.Fprintln(w, "Address Set Hash:", wallet.SetHash)
+		}
+
+		if opts.ShowMasterKeys {
+			fmt.Fprintln(w, "Master xprv:", wallet.MasterXprv)
+
+			fmt.Fprintln(w, "Master xpub:", wallet.MasterXpub)
+		}
+
+		if opts.Derivation {
+			fmt.Fprintln(w, "Coin Type:", wallet.CoinType)
+
+			for _, t := range AddressTypes {
+				fmt.Fprintf(w, "%s Purpose: %d\n", t, wallet.Purposes[t])
+			}
+		}
+
+		for _, set := range wallet.AddressSets() {
+			suffix := ""
+			if opts.Range {
+				suffix = fmt.Sprintf(" #%d", set.Index)
+			}
+
+			kind := "Address"
+			if set.Change != 0 {
+				kind = ChainName(set.Change) + " Address"
+			}
+
+			for _, t := range AddressTypes {
+				fmt.Fprintf(w, "%s %s%s: %s\n", t, kind, suffix, opts.formatAddress(set.Address(t)))
+
+				if opts.ScriptPubKey {
+					script, err := ScriptPubKeyHex(set.Address(t))
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintf(w, "%s scriptPubKey%s: %s\n", t, suffix, script)
+				}
+
+				if opts.ChildXprv {
+					fmt.Fprintf(w, "%s Child xprv%s: %s\n", t, suffix, set.ChildXprvs[t])
+				}
+
+				if opts.PathArray {
+					fmt.Fprintf(w, "%s Path Array%s: %s\n", t, suffix, formatPathArray(PathArray(wallet.Purposes[t], wallet.CoinType, set.Change, set.Index)))
+				}
+
+				if opts.TapTweak && t == AddressTaproot {
+					fmt.Fprintf(w, "%s Tap Tweak%s: %x\n", t, suffix, set.TapTweak)
+				}
+
+				if opts.Witness && t.Bech32() {
+					version, program, err := WitnessProgram(set.Address(t))
+					if err != nil {
+						return err
+					}
+
+					fmt.Fprintf(w, "%s Witness%s: version %d program %s\n", t, suffix, version, program)
+				}
+			}
+		}
+
+		if opts.LegacyBip32 {
+			fmt.Fprintln(w, "BIP-32 Legacy m/0'/0/0 P2PKH Address:", wallet.LegacyBip32)
+		}
+
+		if opts.Tree {
+			fmt.Fprint(w, "Derivation Tree:\n", wallet.Tree)
+		}
+
+		for _, d := range wallet.Descriptors {
+			fmt.Fprintf(w, "%s %s Descriptor: %s\n", d.Type, d.ChainName(), d.Descriptor)
+		}
+
+		if i != len(wallets)-1 {
+			fmt.Fprint(w, opts.Separator)
+		}
+	}
+
+	return nil
+}
+
+func writeCSV(w io.Writer, wallets []Generated, opts OutputOptions) error {
+	writer := csv.NewWriter(w)
+
+	var descriptors []WalletDescriptor
+	if len(wallets) > 0 {
+		descriptors = wallets[0].Descriptors
+	}
+
+	if err := writer.Write(csvHeader(opts, descriptors)); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for i, wallet := range wallets {
+		if err := writeCSVRows(writer, i+1+opts.NumberOffset, wallet, opts); err != nil {
+			return err
+		}
+	}
+
+	// Ensure all data is written
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// csvHeader returns the CSV header row, the descriptor columns follow those
+// of the first wallet
+func csvHeader(opts OutputOptions, descriptors []WalletDescriptor) []string {
+	header := []string{"#", "Legacy, BIP-44 P2PKH Address", "Nested Segwit, BIP-49 P2WPKH-in-P2SH Address", "Native Segwit, BIP-84 P2WPKH Address", "Taproot, BIP-86 P2TR Address", opts.secretLabel()}
+	if opts.Range {
+		header = append(header[:1], append([]string{"Index"}, header[1:]...)...)
+	}
+	if opts.Change {
+		header = append(header[:1], append([]string{"Chain"}, header[1:]...)...)
+	}
+	if opts.Passphrases {
+		header = append(header, "Passphrase #")
+	}
+	if opts.ShowMasterKeys {
+		header = append(header, "Master xprv", "Master xpub")
+	}
+	if opts.LegacyBip32 {
+		header = append(header, "BIP-32 Legacy m/0'/0/0 P2PKH Address")
+	}
+	if opts.Identicon {
+		header = append(header, "Identicon")
+	}
+	if opts.SetHash {
+		header = append(header, "Address Set Hash")
+	}
+	if opts.Derivation {
+		header = append(header, "Coin Type")
+		for _, t := range AddressTypes {
+			header = append(header, fmt.Sprintf("%s Purpose", t))
+		}
+	}
+	if opts.ScriptPubKey {
+		for _, t := range AddressTypes {
+			header = append(header, fmt.Sprintf("%s scriptPubKey", t))
+		}
+	}
+	if opts.Witness {
+		for _, t := range AddressTypes {
+			if t.Bech32() {
+				header = append(header, fmt.Sprintf("%s Witness Version", t), fmt.Sprintf("%s Witness Program", t))
+			}
+		}
+	}
+	if opts.TapTweak {
+		header = append(header, fmt.Sprintf("%s Tap Tweak", AddressTaproot))
+	}
+	if opts.ChildXprv {
+		for _, t := range AddressTypes {
+			header = append(header, fmt.Sprintf("%s Child xprv", t))
+		}
+	}
+	if opts.PathArray {
+		for _, t := range AddressTypes {
+			header = append(header, fmt.Sprintf("%s Path Array", t))
+		}
+	}
+	for _, d := range descriptors {
+		header = append(header, fmt.Sprintf("%s %s Descriptor", d.Type, d.ChainName()))
+	}
+
+	return header
+}
+
+// writeCSVRows writes the rows of the wallet numbered number, one per
+// address set
+func writeCSVRows(writer *csv.Writer, number int, wallet Generated, opts OutputOptions) error {
+	for _, set := range wallet.AddressSets() {
+		row := []string{strconv.Itoa(number)}
+		if opts.Change {
+			row = append(row, ChainName(set.Change))
+		}
+		if opts.Range {
+			row = append(row, strconv.FormatUint(uint64(set.Index), 10))
+		}
+		row = append(row,
+			opts.formatAddress(set.P2pkhAddress),
+			opts.formatAddress(set.P2wpkhP2shAddress),
+			opts.formatAddress(set.P2wpkhAddress),
+			opts.formatAddress(set.TaprootAddress),
+			opts.formatSecret(wallet),
+		)
+		if opts.Passphrases {
+			row = append(row, strconv.Itoa(wallet.PassphraseIndex))
+		}
+		if opts.ShowMasterKeys {
+			row = append(row, wallet.MasterXprv, wallet.MasterXpub)
+		}
+		if opts.LegacyBip32 {
+			row = append(row, wallet.LegacyBip32.EncodeAddress())
+		}
+		if opts.Identicon {
+			row = append(row, wallet.Identicon)
+		}
+		if opts.SetHash {
+			row = append(row, wallet.SetHash)
+		}
+		if opts.Derivation {
+			row = append(row, strconv.FormatUint(uint64(wallet.CoinType), 10))
+			for _, t := range AddressTypes {
+				row = append(row, strconv.FormatUint(uint64(wallet.Purposes[t]), 10))
+			}
+		}
+		if opts.ScriptPubKey {
+			for _, t := range AddressTypes {
+				script, err := ScriptPubKeyHex(set.Address(t))
+				if err != nil {
+					return err
+				}
+
+				row = append(row, script)
+			}
+		}
+		if opts.Witness {
+			for _, t := range AddressTypes {
+				if !t.Bech32() {
+					continue
+				}
+
+				version, program, err := WitnessProgram(set.Address(t))
+				if err != nil {
+					return err
+				}
+
+				row = append(row, strconv.Itoa(int(version)), program)
+			}
+		}
+		if opts.TapTweak {
+			row = append(row, hex.EncodeToString(set.TapTweak))
+		}
+		if opts.ChildXprv {
+			for _, t := range AddressTypes {
+				row = append(row, set.ChildXprvs[t])
+			}
+		}
+		if opts.PathArray {
+			for _, t := range AddressTypes {
+				row = append(row, formatPathArray(PathArray(wallet.Purposes[t], wallet.CoinType, set.Change, set.Index)))
+			}
+		}
+		for _, d := range wallet.Descriptors {
+			row = append(row, d.Descriptor)
+		}
+
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("error writing record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+type addressSetJSON struct {
+	Change            bool                   `json:"change,omitempty"`
+	Chain             uint32                 `json:"chain,omitempty"`
+	Index             uint32                 `json:"index"`
+	P2pkhAddress      string                 `json:"p2pkh_address"`
+	P2wpkhP2shAddress string                 `json:"p2wpkh_p2sh_address"`
+	P2wpkhAddress     string                 `json:"p2wpkh_address"`
+	TaprootAddress    string                 `json:"taproot_address"`
+	ScriptPubKeys     map[string]string      `json:"script_pub_keys,omitempty"`
+	WitnessPrograms   map[string]witnessJSON `json:"witness_programs,omitempty"`
+	TapTweak          string                 `json:"tap_tweak,omitempty"`
+	ChildXprvs        map[string]string      `json:"child_xprvs,omitempty"`
+	PathArrays        map[string][]uint32    `json:"path_arrays,omitempty"`
+}
+
+type witnessJSON struct {
+	Version byte   `json:"version"`
+	Program string `json:"program"`
+}
+
+type descriptorJSON struct {
+	Type       string `json:"type"`
+	Internal   bool   `json:"internal"`
+	Descriptor string `json:"desc"`
+}
+
+type walletJSON struct {
+	Index             int                    `json:"index"`
+	Change            bool                   `json:"change,omitempty"`
+	Chain             uint32                 `json:"chain,omitempty"`
+	P2pkhAddress      string                 `json:"p2pkh_address"`
+	P2wpkhP2shAddress string                 `json:"p2wpkh_p2sh_address"`
+	P2wpkhAddress     string                 `json:"p2wpkh_address"`
+	TaprootAddress    string                 `json:"taproot_address"`
+	Mnemonic          string                 `json:"mnemonic,omitempty"`
+	Seed              string                 `json:"seed,omitempty"`
+	Entropy           string                 `json:"entropy,omitempty"`
+	Aezeed            string                 `json:"aezeed,omitempty"`
+	PassphraseIndex   int                    `json:"passphrase_index,omitempty"`
+	MasterXprv        string                 `json:"master_xprv,omitempty"`
+	MasterXpub        string                 `json:"master_xpub,omitempty"`
+	LegacyBip32       string                 `json:"legacy_bip32_address,omitempty"`
+	Identicon         string                 `json:"identicon,omitempty"`
+	SetHash           string                 `json:"address_set_hash,omitempty"`
+	CoinType          *uint32                `json:"coin_type,omitempty"`
+	Purposes          map[string]uint32      `json:"purposes,omitempty"`
+	ScriptPubKeys     map[string]string      `json:"script_pub_keys,omitempty"`
+	WitnessPrograms   map[string]witnessJSON `json:"witness_programs,omitempty"`
+	TapTweak          string                 `json:"tap_tweak,omitempty"`
+	ChildXprvs        map[string]string      `json:"child_xprvs,omitempty"`
+	PathArrays        map[string][]uint32    `json:"path_arrays,omitempty"`
+	Addresses         []addressSetJSON       `json:"addresses,omitempty"`
+	Descriptors       []descriptorJSON       `json:"descriptors,omitempty"`
+}
+
+// nonstandardChain returns the chain number JSON output adds to the change
+// flag for chains other than receive and change, 0 otherwise
+func nonstandardChain(change uint32) uint32 {
+	if change > 1 {
+		return change
+	}
+
+	return 0
+}
+
+// scriptPubKeys returns the hex output script of each address keyed by type name
+func scriptPubKeys(set AddressSet) (map[string]string, error) {
+	scripts := make(map[string]string)
+	for _, t := range AddressTypes {
+		script, err := ScriptPubKeyHex(set.Address(t))
+		if err != nil {
+			return nil, err
+		}
+
+		scripts[t.Name()] = script
+	}
+
+	return scripts, nil
+}
+
+// witnessPrograms returns the witness version and program of each bech32
+// address keyed by type name
+func witnessPrograms(set AddressSet) (map[string]witnessJSON, error) {
+	programs := make(map[string]witnessJSON)
+	for _, t := range AddressTypes {
+		if !t.Bech32() {
+			continue
+		}
+
+		version, program, err := WitnessProgram(set.Address(t))
+		if err != nil {
+			return nil, err
+		}
+
+		programs[t.Name()] = witnessJSON{Version: version, Program: program}
+	}
+
+	return programs, nil
+}
+
+// pathArraysJSON keys the path arrays of the set by type name
+func pathArraysJSON(wallet Generated, set AddressSet) map[string][]uint32 {
+	arrays := make(map[string][]uint32)
+	for _, t := range AddressTypes {
+		arrays[t.Name()] = PathArray(wallet.Purposes[t], wallet.CoinType, set.Change, set.Index)
+	}
+
+	return arrays
+}
+
+// childXprvsJSON keys the child xprvs by type name
+func childXprvsJSON(xprvs map[AddressType]string) map[string]string {
+	named := make(map[string]string)
+	for t, xprv := range xprvs {
+		named[t.Name()] = xprv
+	}
+
+	return named
+}
+
+// walletRecords builds the records of the JSON output, shared by the other
+// structured formats
+func walletRecords(wallets []Generated, opts OutputOptions) ([]walletJSON, error) {
+	records := make([]walletJSON, 0, len(wallets))
+
+	for i, wallet := range wallets {
+		record := walletJSON{
+			Index:             i + 1 + opts.NumberOffset,
+			Change:            wallet.Change != 0,
+			Chain:             nonstandardChain(wallet.Change),
+			P2pkhAddress:      wallet.P2pkhAddress.EncodeAddress(),
+			P2wpkhP2shAddress: wallet.P2wpkhP2shAddress.EncodeAddress(),
+			P2wpkhAddress:     wallet.P2wpkhAddress.EncodeAddress(),
+			TaprootAddress:    wallet.TaprootAddress.EncodeAddress(),
+		}
+		switch opts.SeedFormat {
+		case SeedFormatHex:
+			record.Seed = wallet.Secret
+		case SeedFormatEntropyHex:
+			record.Entropy = wallet.Secret
+		case SeedFormatAezeed:
+			record.Aezeed = opts.formatMnemonic(wallet.Mnemonic)
+		default:
+			record.Mnemonic = opts.formatMnemonic(wallet.Mnemonic)
+		}
+		if opts.Passphrases {
+			record.PassphraseIndex = wallet.PassphraseIndex
+		}
+		if opts.ShowMasterKeys {
+			record.MasterXprv = wallet.MasterXprv
+			record.MasterXpub = wallet.MasterXpub
+		}
+		if opts.LegacyBip32 {
+			record.LegacyBip32 = wallet.LegacyBip32.EncodeAddress()
+		}
+		if opts.Identicon {
+			record.Identicon = wallet.Identicon
+		}
+		if opts.SetHash {
+			record.SetHash = wallet.SetHash
+		}
+		if opts.Derivation {
+			coinType := wallet.CoinType
+			record.CoinType = &coinType
+			record.Purposes = make(map[string]uint32)
+			for _, t := range AddressTypes {
+				record.Purposes[t.Name()] = wallet.Purposes[t]
+			}
+		}
+		if opts.ScriptPubKey {
+			scripts, err := scriptPubKeys(wallet.AddressSets()[0])
+			if err != nil {
+				return nil, err
+			}
+
+			record.ScriptPubKeys = scripts
+		}
+		if opts.Witness {
+			programs, err := witnessPrograms(wallet.AddressSets()[0])
+			if err != nil {
+				return nil, err
+			}
+
+			record.WitnessPrograms = programs
+		}
+		if opts.TapTweak {
+			record.TapTweak = hex.EncodeToString(wallet.TapTweak)
+		}
+		if opts.ChildXprv {
+			record.ChildXprvs = childXprvsJSON(wallet.ChildXprvs)
+		}
+		if opts.PathArray {
+			record.PathArrays = pathArraysJSON(wallet, wallet.AddressSets()[0])
+		}
+		if opts.Range {
+			for _, set := range wallet.Addresses {
+				setRecord := addressSetJSON{
+					Change:            set.Change != 0,
+					Chain:             nonstandardChain(set.Change),
+					Index:             set.Index,
+					P2pkhAddress:      set.P2pkhAddress.EncodeAddress(),
+					P2wpkhP2shAddress: set.P2wpkhP2shAddress.EncodeAddress(),
+					P2wpkhAddress:     set.P2wpkhAddress.EncodeAddress(),
+					TaprootAddress:    set.TaprootAddress.EncodeAddress(),
+				}
+				if opts.ScriptPubKey {
+					scripts, err := scriptPubKeys(set)
+					if err != nil {
+						return nil, err
+					}
+
+					setRecord.ScriptPubKeys = scripts
+				}
+				if opts.Witness {
+					programs, err := witnessPrograms(set)
+					if err != nil {
+						return nil, err
+					}
+
+					setRecord.WitnessPrograms = programs
+				}
+				if opts.TapTweak {
+					setRecord.TapTweak = hex.EncodeToString(set.TapTweak)
+				}
+				if opts.ChildXprv {
+					setRecord.ChildXprvs = childXprvsJSON(set.ChildXprvs)
+				}
+				if opts.PathArray {
+					setRecord.PathArrays = pathArraysJSON(wallet, set)
+				}
+
+				record.Addresses = append(record.Addresses, setRecord)
+			}
+		}
+
+		for _, d := range wallet.Descriptors {
+			record.Descriptors = append(record.Descriptors, descriptorJSON{
+				Type:       d.Type.Name(),
+				Internal:   d.Change == 1,
+				Descriptor: d.Descriptor,
+			})
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func writeJSON(w io.Writer, wallets []Generated, opts OutputOptions) error {
+	records, err := walletRecords(wallets, opts)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestHiddenWallets checks that two passphrases over one mnemonic derive
+// distinct addresses, and that every output format labels the hidden wallets
+// by index without the passphrase
+func TestHiddenWallets(t *testing.T) {
+	passphrases := []string{"", "hidden wallet passphrase"}
+
+	var wallets []Generated
+	for i, passphrase := range passphrases {
+		wallet, err := NewWalletFromMnemonic(bip86Mnemonic, passphrase, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		set, err := wallet.DeriveAll(0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wallets = append(wallets, Generated{
+			P2pkhAddress:      set.P2pkhAddress,
+			P2wpkhP2shAddress: set.P2wpkhP2shAddress,
+			P2wpkhAddress:     set.P2wpkhAddress,
+			TaprootAddress:    set.TaprootAddress,
+			Mnemonic:          wallet.Mnemonic,
+			PassphraseIndex:   i + 1,
+		})
+	}
+
+	for _, typ := range AddressTypes {
+		if wallets[0].AddressSets()[0].Address(typ).EncodeAddress() == wallets[1].AddressSets()[0].Address(typ).EncodeAddress() {
+			t.Fatalf("passphrases derived the same %s address", typ)
+		}
+	}
+
+	labels := map[string]string{"text": "Passphrase: #2", "csv": ",2\n", "json": `"passphrase_index": 2`}
+	for format, label := range labels {
+		var out bytes.Buffer
+		if err := WriteWallets(&out, format, wallets, OutputOptions{Passphrases: true, Separator: "\n"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out.String(), label) {
+			t.Fatalf("%s output does not label the passphrase index", format)
+		}
+
+		if strings.Contains(out.String(), passphrases[1]) {
+			t.Fatalf("%s output contains the passphrase", format)
+		}
+	}
+}
+
+// TestRedact checks -redact masks the mnemonic in every output format
+func TestRedact(t *testing.T) {
+	wallet := testWallet(t)
+
+	if redacted := RedactMnemonic("army van defense carry jealous true garbage claim echo media make crunch"); redacted != "army ******** crunch" {
+		t.Fatalf("redacted mnemonic %q, expected %q", redacted, "army ******** crunch")
+	}
+
+	set, err := wallet.DeriveAll(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wallets := []Generated{{
+		P2pkhAddress:      set.P2pkhAddress,
+		P2wpkhP2shAddress: set.P2wpkhP2shAddress,
+		P2wpkhAddress:     set.P2wpkhAddress,
+		TaprootAddress:    set.TaprootAddress,
+		Mnemonic:          wallet.Mnemonic,
+	}}
+
+	for _, format := range []string{"text", "table", "csv", "json"} {
+		var out bytes.Buffer
+		if err := WriteWallets(&out, format, wallets, OutputOptions{Redact: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(out.String(), wallet.Mnemonic) || !strings.Contains(out.String(), RedactMnemonic(wallet.Mnemonic)) {
+			t.Fatalf("%s output does not redact the mnemonic", format)
+		}
+	}
+}
+
+// TestCountFrom checks an offset continues the wallet numbers of batch and
+// streamed CSV output alike
+func TestCountFrom(t *testing.T) {
+	wallet := testWallet(t)
+
+	set, err := wallet.DeriveAll(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated := Generated{
+		P2pkhAddress:      set.P2pkhAddress,
+		P2wpkhP2shAddress: set.P2wpkhP2shAddress,
+		P2wpkhAddress:     set.P2wpkhAddress,
+		TaprootAddress:    set.TaprootAddress,
+		Mnemonic:          wallet.Mnemonic,
+	}
+	opts := OutputOptions{NumberOffset: 100}
+
+	var batch bytes.Buffer
+	if err := WriteWallets(&batch, "csv", []Generated{generated, generated}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var streamed bytes.Buffer
+	stream, err := NewWalletStream(&streamed, "csv", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := stream.Write(generated); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, out := range map[string]string{"batch": batch.String(), "streamed": streamed.String()} {
+		var numbers []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			numbers = append(numbers, strings.SplitN(line, ",", 2)[0])
+		}
+
+		if !slices.Equal(numbers, []string{"101", "102"}) {
+			t.Fatalf("%s CSV numbered %v, expected [101 102]", name, numbers)
+		}
+	}
+}
+
+// separatedWallet is the text output of a wallet holding the first receive
+// addresses of bip86Mnemonic
+const separatedWallet = `Mnemonic: abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about
+BIP-44 P2PKH Address: 1LqBGSKuX5yYUonjxT5qGfpUsXKYYWeabA
+BIP-49 P2WPKH-in-P2SH Address: 37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf
+BIP-84 P2WPKH Address: bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu
+BIP-86 P2TR Address: bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr
+`
+
+// TestSeparator checks -separator is written between the wallets of text
+// output only, never before the first or after the last, in batch and
+// streamed output alike
+func TestSeparator(t *testing.T) {
+	wallet := testWallet(t)
+
+	set, err := wallet.DeriveAll(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generated := Generated{
+		P2pkhAddress:      set.P2pkhAddress,
+		P2wpkhP2shAddress: set.P2wpkhP2shAddress,
+		P2wpkhAddress:     set.P2wpkhAddress,
+		TaprootAddress:    set.TaprootAddress,
+		Mnemonic:          wallet.Mnemonic,
+	}
+	opts := OutputOptions{Separator: "--\n"}
+
+	for _, v := range []struct {
+		count    int
+		expected string
+	}{
+		{1, separatedWallet},
+		{2, separatedWallet + "--\n" + separatedWallet},
+		{3, separatedWallet + "--\n" + separatedWallet + "--\n" + separatedWallet},
+	} {
+		var wallets []Generated
+		for range v.count {
+			wallets = append(wallets, generated)
+		}
+
+		var batch bytes.Buffer
+		if err := WriteWallets(&batch, "text", wallets, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		var streamed bytes.Buffer
+		stream, err := NewWalletStream(&streamed, "text", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, w := range wallets {
+			if err := stream.Write(w); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for name, out := range map[string]string{"batch": batch.String(), "streamed": streamed.String()} {
+			if out != v.expected {
+				t.Errorf("%s output of %d wallets\n%q\nexpected\n%q", name, v.count, out, v.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/skip2/go-qrcode"
+)
+
+const qrImageSize = 256
+
+var paperTemplate = template.Must(template.New("paper").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Paper Wallet</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.wallet { page-break-after: always; }
+.warning { border: 2px solid #c00; color: #c00; padding: 0.5em 1em; margin-bottom: 1em; }
+.address { display: inline-block; width: 45%; margin: 0.5em; vertical-align: top; }
+.address code { display: block; word-break: break-all; }
+.mnemonic { font-family: monospace; font-size: 1.2em; }
+.hint { color: #666; }
+@media print { .hint { display: none; } }
+</style>
+</head>
+<body>
+<p class="hint">To create a PDF, print this page and choose "Save as PDF". Do not print on a shared or networked printer.</p>
+{{range .}}
+<div class="wallet">
+<h1>Wallet #{{.Index}} ({{.Network}})</h1>
+<div class="warning">
+<strong>SENSITIVE:</strong> anyone who sees the mnemonic below can spend all funds of this wallet.
+Store this page offline, never photograph it and destroy any digital copies of this file.
+</div>
+{{range .Addresses}}
+<div class="address">
+<h3>{{.Label}}</h3>
+<img src="{{.QR}}" alt="{{.Address}}">
+<code>{{.Address}}</code>
+</div>
+{{end}}
+<h2>Mnemonic</h2>
+<img src="{{.MnemonicQR}}" alt="Mnemonic QR code">
+<p class="mnemonic">{{.Mnemonic}}</p>
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+type paperAddress struct {
+	Label   string
+	Address string
+	QR      template.URL
+}
+
+type paperWallet struct {
+	Index      int
+	Network    string
+	Mnemonic   string
+	MnemonicQR template.URL
+	Addresses  []paperAddress
+}
+
+// qrDataURL encodes content as a QR code PNG embedded in a data URL
+func qrDataURL(content string) (template.URL, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, qrImageSize)
+	if err != nil {
+		return "", fmt.Errorf("error encoding QR code: %w", err)
+	}
+
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)), nil
+}
+
+// MnemonicQRSize returns the version of the QR code -paper encodes the mnemonic
+// in and its width in modules without the quiet zone. Every version adds four
+// modules, 12 word mnemonics give the smallest codes.
+func MnemonicQRSize(mnemonic string) (version int, modules int, err error) {
+	code, err := qrcode.New(mnemonic, qrcode.Medium)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error encoding QR code: %w", err)
+	}
+
+	return code.VersionNumber, 17 + 4*code.VersionNumber, nil
+}
+
+// WritePaperWallets renders a printable HTML page per wallet with QR codes of
+// each address and of the mnemonic, numbering the pages from first. With a
+// payment request the address QR codes encode its BIP-21 URI.
+func WritePaperWallets(fileName string, network string, first int, wallets []Generated, request *PaymentRequest) error {
+	var pages []paperWallet
+
+	for i, wallet := range wallets {
+		mnemonicQR, err := qrDataURL(wallet.Mnemonic)
+		if err != nil {
+			return err
+		}
+
+		page := paperWallet{
+			Index:      first + i,
+			Network:    network,
+			Mnemonic:   wallet.Mnemonic,
+			MnemonicQR: mnemonicQR,
+		}
+
+		addresses := []struct {
+			label   string
+			address btcutil.Address
+		}{
+			{"BIP-44 P2PKH", wallet.P2pkhAddress},
+			{"BIP-49 P2WPKH-in-P2SH", wallet.P2wpkhP2shAddress},
+			{"BIP-84 P2WPKH", wallet.P2wpkhAddress},
+			{"BIP-86 P2TR", wallet.TaprootAddress},
+		}
+
+		for _, a := range addresses {
+			content := a.address.EncodeAddress()
+			if request != nil {
+				content = request.URI(a.address)
+			}
+
+			qr, err := qrDataURL(content)
+			if err != nil {
+				return err
+			}
+
+			label := a.label
+			if wallet.Change != 0 {
+				label += " (change)"
+			}
+
+			page.Addresses = append(page.Addresses, paperAddress{Label: label, Address: a.address.EncodeAddress(), QR: qr})
+		}
+
+		pages = append(pages, page)
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	if err := paperTemplate.Execute(file, pages); err != nil {
+		return fmt.Errorf("error rendering paper wallet: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestMnemonicQR pins the QR size reported by -qr for the test mnemonic
+func TestMnemonicQR(t *testing.T) {
+	version, modules, err := MnemonicQRSize(bip86Mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if version != 6 || modules != 41 {
+		t.Fatalf("mnemonic QR version %d with %d modules, expected version 6 with 41", version, modules)
+	}
+}
+
+// TestWritePaperWallets renders a paper wallet and checks the page holds the
+// mnemonic, its QR code, the warning and the -count-from number. The template
+// escapes the + of the base64 QR codes, so the page is unescaped first.
+func TestWritePaperWallets(t *testing.T) {
+	c := &generateCommand{params: &chaincfg.MainNetParams, seedFormat: SeedFormatMnemonic, addresses: 1}
+	wallet, err := c.deriveWallet(0, testWallet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "paper.html")
+	if err := WritePaperWallets(path, "mainnet", 5, []Generated{wallet}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := html.UnescapeString(string(data))
+
+	qr, err := qrDataURL(bip86Mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		bip86Mnemonic,
+		`src="` + string(qr) + `"`,
+		"<strong>SENSITIVE:</strong>",
+		"Wallet #5 (mainnet)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("paper wallet is missing %.80q", want)
+		}
+	}
+}
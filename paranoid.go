@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// decodedType returns the address type a decoded address belongs to
+func decodedType(address btcutil.Address) (AddressType, bool) {
+	switch address.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return AddressP2PKH, true
+	case *btcutil.AddressScriptHash:
+		return AddressP2WPKHInP2SH, true
+	case *btcutil.AddressWitnessPubKeyHash:
+		return AddressP2WPKH, true
+	case *btcutil.AddressTaproot:
+		return AddressTaproot, true
+	default:
+		return 0, false
+	}
+}
+
+// CheckAddressRoundTrip decodes the encoded address with the independent
+// btcutil decoder and checks that it yields the same string and type on the
+// network, catching an encoder and decoder mismatch at generation time
+func CheckAddressRoundTrip(t AddressType, address btcutil.Address, params *chaincfg.Params) error {
+	encoded := address.EncodeAddress()
+
+	decoded, err := btcutil.DecodeAddress(encoded, params)
+	if err != nil {
+		return fmt.Errorf("%s address %s does not decode: %w", t, encoded, err)
+	}
+
+	if decoded.EncodeAddress() != encoded {
+		return fmt.Errorf("%s address %s decodes to %s", t, encoded, decoded.EncodeAddress())
+	}
+
+	if decodedType, ok := decodedType(decoded); !ok || decodedType != t {
+		return fmt.Errorf("%s address %s decodes to a %T", t, encoded, decoded)
+	}
+
+	if !decoded.IsForNet(params) {
+		return fmt.Errorf("%s address %s is not for network %s", t, encoded, params.Name)
+	}
+
+	return nil
+}
+
+// CheckRoundTrip round trips the address of every type in the set
+func (s AddressSet) CheckRoundTrip(params *chaincfg.Params) error {
+	for _, t := range AddressTypes {
+		if err := CheckAddressRoundTrip(t, s.Address(t), params); err != nil {
+			return fmt.Errorf("index %d: %w", s.Index, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+// corruptedAddress encodes its address with the last character replaced,
+// breaking the checksum
+type corruptedAddress struct {
+	btcutil.Address
+}
+
+func (a corruptedAddress) EncodeAddress() string {
+	encoded := a.Address.EncodeAddress()
+	last := "q"
+	if strings.HasSuffix(encoded, last) {
+		last = "p"
+	}
+
+	return encoded[:len(encoded)-1] + last
+}
+
+// TestRoundTrip checks that the -paranoid round trip passes for the address
+// of every type and fails once the address is corrupted
+func TestRoundTrip(t *testing.T) {
+	wallet := testWallet(t)
+
+	set, err := wallet.DeriveAll(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := set.CheckRoundTrip(wallet.Params); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, typ := range AddressTypes {
+		if err := CheckAddressRoundTrip(typ, corruptedAddress{set.Address(typ)}, wallet.Params); err == nil {
+			t.Fatalf("%s: corrupted address %s passed the round trip", typ, corruptedAddress{set.Address(typ)}.EncodeAddress())
+		}
+	}
+}
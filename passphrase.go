@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ReadPassphraseFile reads a BIP-39 passphrase from a file, e.g. on removable
+// media, so it does not appear in the shell history or process list. A single
+// trailing newline is removed, any other whitespace is part of the passphrase.
+func ReadPassphraseFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading passphrase file: %w", err)
+	}
+
+	passphrase := string(data)
+	if strings.HasSuffix(passphrase, "\r\n") {
+		passphrase = strings.TrimSuffix(passphrase, "\r\n")
+	} else {
+		passphrase = strings.TrimSuffix(passphrase, "\n")
+	}
+
+	return passphrase, nil
+}
+
+// passphraseFlags collects repeated -passphrase flags
+type passphraseFlags []string
+
+// String returns nothing so a passphrase never shows up in the usage output
+func (p *passphraseFlags) String() string {
+	return ""
+}
+
+func (p *passphraseFlags) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// resolvePassphrase replaces the passphrase with the content of
+// passphraseFile if one is given. The passphrase itself is never logged.
+func resolvePassphrase(passphrase *string, passphraseFile string) error {
+	if len(passphraseFile) == 0 {
+		return nil
+	}
+
+	if len(*passphrase) > 0 {
+		return fmt.Errorf("-passphrase and -passphrase-file cannot be combined")
+	}
+
+	value, err := ReadPassphraseFile(passphraseFile)
+	if err != nil {
+		return err
+	}
+	*passphrase = value
+
+	slog.Debug("read passphrase", "file", passphraseFile)
+
+	return nil
+}
+
+// Environment variables supplying -mnemonic and -passphrase, e.g. secrets
+// injected into a container by the orchestrator
+const (
+	mnemonicEnv   = "BTC_WALLET_MNEMONIC"
+	passphraseEnv = "BTC_WALLET_PASSPHRASE"
+)
+
+// secretEnv is a flag that falls back to an environment variable unless one
+// of the overriding flags was given
+type secretEnv struct {
+	flag      string
+	env       string
+	overrides []string
+}
+
+var (
+	passphraseSecretEnv = secretEnv{"passphrase", passphraseEnv, []string{"passphrase", "passphrase-file"}}
+	mnemonicSecretEnv   = secretEnv{"mnemonic", mnemonicEnv, []string{"mnemonic", "mnemonics-file", "word-indices", "entropy-file", "xpub"}}
+)
+
+// applySecretEnv sets the -passphrase flag of fs, and the -mnemonic flag if
+// the command expects a mnemonic, from their environment variables. Flags
+// supplying the same secret take precedence, and a mnemonic left in the
+// environment never turns generate into a restore. The values are never
+// logged.
+func applySecretEnv(fs *flag.FlagSet, mnemonic bool) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	sources := []secretEnv{passphraseSecretEnv}
+	if mnemonic {
+		sources = append(sources, mnemonicSecretEnv)
+	}
+
+	for _, source := range sources {
+		if fs.Lookup(source.flag) == nil {
+			continue
+		}
+
+		value, ok := os.LookupEnv(source.env)
+		if !ok || len(value) == 0 || slices.ContainsFunc(source.overrides, func(name string) bool { return set[name] }) {
+			continue
+		}
+
+		if err := fs.Set(source.flag, value); err != nil {
+			return fmt.Errorf("error applying %s: %w", source.env, err)
+		}
+
+		slog.Debug("read secret from environment", "flag", source.flag, "env", source.env)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// parseSecretFlags parses args into a flag set with the secret flags of the
+// subcommands and applies the environment to it
+func parseSecretFlags(t *testing.T, args []string, mnemonic bool) (string, string) {
+	t.Helper()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	m := fs.String("mnemonic", "", "")
+	p := fs.String("passphrase", "", "")
+	fs.String("passphrase-file", "", "")
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applySecretEnv(fs, mnemonic); err != nil {
+		t.Fatal(err)
+	}
+
+	return *m, *p
+}
+
+// TestSecretEnv checks that a wallet restored from BTC_WALLET_MNEMONIC and
+// BTC_WALLET_PASSPHRASE matches the one restored from the flags
+func TestSecretEnv(t *testing.T) {
+	t.Setenv(mnemonicEnv, bip86Mnemonic)
+	t.Setenv(passphraseEnv, "TREZOR")
+
+	fromFlags, err := NewWalletFromMnemonic(bip86Mnemonic, "TREZOR", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mnemonic, passphrase := parseSecretFlags(t, nil, true)
+	fromEnv, err := NewWalletFromMnemonic(mnemonic, passphrase, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("error restoring wallet from %s: %v", mnemonicEnv, err)
+	}
+
+	if fromEnv.MasterKey.String() != fromFlags.MasterKey.String() {
+		t.Errorf("wallet restored from %s and %s differs from the flags", mnemonicEnv, passphraseEnv)
+	}
+}
+
+// TestSecretEnvPrecedence checks the flags take precedence over the
+// environment and the mnemonic is only read where a command expects one
+func TestSecretEnvPrecedence(t *testing.T) {
+	t.Setenv(mnemonicEnv, bip86Mnemonic)
+	t.Setenv(passphraseEnv, "TREZOR")
+
+	for _, v := range []struct {
+		name       string
+		args       []string
+		mnemonic   bool
+		expected   string
+		passphrase string
+	}{
+		{"flags", []string{"-mnemonic", "zoo", "-passphrase", ""}, true, "zoo", ""},
+		{"passphrase file", []string{"-passphrase-file", "passphrase.txt"}, true, bip86Mnemonic, ""},
+		{"no mnemonic expected", nil, false, "", "TREZOR"},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			mnemonic, passphrase := parseSecretFlags(t, v.args, v.mnemonic)
+			if mnemonic != v.expected || passphrase != v.passphrase {
+				t.Errorf("mnemonic %q and passphrase %q, expected %q and %q", mnemonic, passphrase, v.expected, v.passphrase)
+			}
+		})
+	}
+}
+
+// accentedSeed is the BIP-39 seed of bip86Mnemonic with the passphrase
+// "Crème brûlée", computed independently with Python's hashlib.pbkdf2_hmac
+// over the NFKD form of the passphrase
+const accentedSeed = "6021a8cfcd2724cb0db7f3d512d8524064e4cc9e7848ddd9f7a428a73ada0f8b998f557c835ebac454923b1af5a8b9c75ae8f0a233251b327a05098761ceeeb2"
+
+// TestPassphraseNFKD checks the composed and decomposed spellings of an
+// accented passphrase both derive the seed of its NFKD form
+func TestPassphraseNFKD(t *testing.T) {
+	for _, passphrase := range []string{"Cr\u00e8me br\u00fbl\u00e9e", "Cre\u0300me bru\u0302le\u0301e"} {
+		wallet, err := NewWalletFromMnemonic(bip86Mnemonic, passphrase, &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if seed := hex.EncodeToString(wallet.Seed); seed != accentedSeed {
+			t.Errorf("passphrase %+q derived seed %s, expected %s", passphrase, seed, accentedSeed)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// BuildPSBT creates an unsigned PSBT spending the UTXOs to the outputs. Each
+// input carries its previous output and the BIP-32 derivation of its key, so
+// a signer holding the mnemonic can sign it. Inputs signal replace-by-fee.
+func (w *Wallet) BuildPSBT(utxos []UTXO, outputs []*wire.TxOut) (*psbt.Packet, error) {
+	fingerprint, err := w.Fingerprint()
+	if err != nil {
+		return nil, err
+	}
+
+	outpoints := make([]*wire.OutPoint, 0, len(utxos))
+	sequences := make([]uint32, 0, len(utxos))
+	for i := range utxos {
+		outpoints = append(outpoints, &utxos[i].OutPoint)
+		sequences = append(sequences, wire.MaxTxInSequenceNum-2)
+	}
+
+	packet, err := psbt.New(outpoints, outputs, 2, 0, sequences)
+	if err != nil {
+		return nil, fmt.Errorf("error creating PSBT: %w", err)
+	}
+
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return nil, fmt.Errorf("error creating PSBT updater: %w", err)
+	}
+
+	for i, utxo := range utxos {
+		if err := w.addPSBTInput(updater, i, utxo, binary.LittleEndian.Uint32(fingerprint)); err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+
+	if err := packet.SanityCheck(); err != nil {
+		return nil, fmt.Errorf("invalid PSBT: %w", err)
+	}
+
+	return packet, nil
+}
+
+// addPSBTInput adds the previous output, scripts and key derivation of the
+// UTXO to the input at index i
+func (w *Wallet) addPSBTInput(updater *psbt.Updater, i int, utxo UTXO, fingerprint uint32) error {
+	t, err := w.UTXOType(utxo)
+	if err != nil {
+		return err
+	}
+
+	key, err := w.ExtendMasterKey(t.Purpose(), utxo.Change, utxo.Index)
+	if err != nil {
+		return fmt.Errorf("error extending master key: %w", err)
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return fmt.Errorf("error getting public key: %w", err)
+	}
+
+	path := []uint32{
+		hdkeychain.HardenedKeyStart + t.Purpose(),
+		hdkeychain.HardenedKeyStart + w.Params.HDCoinType,
+		hdkeychain.HardenedKeyStart + 0,
+		utxo.Change,
+		utxo.Index,
+	}
+
+	// Legacy inputs need the whole previous transaction, SegWit inputs only
+	// the spent output since the signature commits to its amount
+	if t == AddressP2PKH {
+		if utxo.PrevTx == nil {
+			return fmt.Errorf("spending the %s output %s requires prev_tx", t, utxo.OutPoint)
+		}
+
+		if err := updater.AddInNonWitnessUtxo(utxo.PrevTx, i); err != nil {
+			return fmt.Errorf("error adding previous transaction: %w", err)
+		}
+	} else {
+		txOut := wire.NewTxOut(int64(utxo.Amount), utxo.PkScript)
+		if err := updater.AddInWitnessUtxo(txOut, i); err != nil {
+			return fmt.Errorf("error adding previous output: %w", err)
+		}
+	}
+
+	switch t {
+	case AddressTaproot:
+		xOnly := schnorr.SerializePubKey(pubKey)
+
+		input := &updater.Upsbt.Inputs[i]
+		input.TaprootInternalKey = xOnly
+		input.TaprootBip32Derivation = append(input.TaprootBip32Derivation, &psbt.TaprootBip32Derivation{
+			XOnlyPubKey:          xOnly,
+			MasterKeyFingerprint: fingerprint,
+			Bip32Path:            path,
+		})
+
+		return nil
+	case AddressP2WPKHInP2SH:
+		witnessPubKeyHash, err := w.witnessPubKeyHash(key)
+		if err != nil {
+			return err
+		}
+
+		redeemScript, err := nestedRedeemScript(witnessPubKeyHash)
+		if err != nil {
+			return err
+		}
+
+		if err := updater.AddInRedeemScript(redeemScript, i); err != nil {
+			return fmt.Errorf("error adding redeem script: %w", err)
+		}
+	}
+
+	if err := updater.AddInBip32Derivation(fingerprint, path, pubKey.SerializeCompressed(), i); err != nil {
+		return fmt.Errorf("error adding key derivation: %w", err)
+	}
+
+	return nil
+}
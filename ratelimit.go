@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limiter paces explorer requests, Wait blocks until the next one may be sent
+type Limiter interface {
+	Wait()
+}
+
+// TokenBucket is a Limiter allowing Rate requests per second on average in
+// bursts of up to Burst. Now and Sleep default to the system clock and are
+// replaced by the tests to run without waiting.
+type TokenBucket struct {
+	Rate  float64
+	Burst float64
+	Now   func() time.Time
+	Sleep func(time.Duration)
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a limiter of rate requests per second without bursts
+func NewTokenBucket(rate float64) (*TokenBucket, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("invalid rate %g: must be positive", rate)
+	}
+
+	return &TokenBucket{Rate: rate, Burst: 1, Now: time.Now, Sleep: time.Sleep, tokens: 1}, nil
+}
+
+func (b *TokenBucket) Wait() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.Now()
+	if !b.last.IsZero() {
+		b.tokens = min(b.Burst, b.tokens+now.Sub(b.last).Seconds()*b.Rate)
+	}
+	b.last = now
+
+	// Sleep until the missing part of a token has accrued
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / b.Rate * float64(time.Second))
+		b.Sleep(wait)
+		b.tokens = 1
+		b.last = now.Add(wait)
+	}
+
+	b.tokens--
+}
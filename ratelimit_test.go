@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRateLimit runs a token bucket on a fake clock and an explorer against
+// a local server answering 429 twice, checking the waits without sleeping
+func TestRateLimit(t *testing.T) {
+	discardLogs(t)
+
+	var now time.Time
+	var slept []time.Duration
+	sleep := func(d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	}
+
+	bucket, err := NewTokenBucket(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bucket.Now = func() time.Time { return now }
+	bucket.Sleep = sleep
+
+	// The first request is free, the next four each wait half a second
+	for i := 0; i < 5; i++ {
+		bucket.Wait()
+	}
+	if !slices.Equal(slept, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}) {
+		t.Fatalf("token bucket slept %v, expected four times 500ms", slept)
+	}
+
+	// An idle second refills the bucket
+	now = now.Add(time.Second)
+	slept = nil
+	bucket.Wait()
+	if len(slept) != 0 {
+		t.Fatalf("token bucket slept %v after idling", slept)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{"chain_stats":{"tx_count":2},"mempool_stats":{"tx_count":1}}`)
+		}
+	}))
+	defer server.Close()
+
+	explorer, err := NewRateLimitedExplorer(server.URL, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	explorer.Limiter = bucket
+	explorer.Sleep = sleep
+	slept = nil
+
+	count, err := explorer.TxCount(bip84Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The retries wait for Retry-After, then twice the 1s backoff, and each
+	// request also waits for the limiter
+	expected := []time.Duration{500 * time.Millisecond, 3 * time.Second, 2 * time.Second}
+	if count != 3 || requests != 3 || !slices.Equal(slept, expected) {
+		t.Fatalf("rate limited explorer counted %d in %d requests sleeping %v, expected 3 in 3 sleeping %v", count, requests, slept, expected)
+	}
+
+	explorer.Attempts = 1
+	requests = 0
+	if _, err := explorer.TxCount(bip84Address); err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("exhausted retries returned %v, expected the 429 status", err)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// AddressMatch describes where a known address was found
+type AddressMatch struct {
+	Network string
+	Params  *chaincfg.Params
+	Type    AddressType
+	Index   uint32
+	Path    string
+}
+
+// FindAddressNetwork derives the first gapLimit addresses of every type on
+// every known network from the mnemonic and returns where the expected address
+// is found. Mnemonics carry no network information, so this helps users who
+// restored with the wrong network. Networks refused by CheckMainnetAllowed
+// are skipped, and their error is returned if the address is not found.
+func FindAddressNetwork(mnemonic string, passphrase string, expected string, gapLimit uint32, confirmMainnet bool) (*AddressMatch, error) {
+	var guard error
+	for _, name := range networkNames {
+		if err := CheckMainnetAllowed(networks[name], confirmMainnet); err != nil {
+			guard = err
+			continue
+		}
+
+		match, err := FindAddress(mnemonic, passphrase, networks[name], expected, gapLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		if match != nil {
+			match.Network = name
+			return match, nil
+		}
+	}
+
+	if guard != nil {
+		return nil, fmt.Errorf("address %s not found in the first %d indices of any type or allowed network: %w", expected, gapLimit, guard)
+	}
+
+	return nil, fmt.Errorf("address %s not found in the first %d indices of any type or network", expected, gapLimit)
+}
+
+// FindAddress derives the first gapLimit receive addresses of every type on
+// the network and returns where the expected address is found, or nil
+func FindAddress(mnemonic string, passphrase string, params *chaincfg.Params, expected string, gapLimit uint32) (*AddressMatch, error) {
+	wallet, err := NewWalletFromMnemonic(mnemonic, passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range AddressTypes {
+		for index := uint32(0); index < gapLimit; index++ {
+			address, err := wallet.DeriveAddress(t, 0, index)
+			if err != nil {
+				return nil, fmt.Errorf("error deriving %s address: %w", t, err)
+			}
+
+			if address.EncodeAddress() == expected {
+				return &AddressMatch{
+					Network: params.Name,
+					Params:  params,
+					Type:    t,
+					Index:   index,
+					Path:    wallet.DerivationPath(t.Purpose(), 0, index),
+				}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// findPathProgress is the number of indices between progress logs of FindPath
+const findPathProgress = 10000
+
+// FindPath searches the receive and change chains of the purpose matching the
+// address type for addr, up to and including maxIndex, and returns its
+// derivation path, e.g. m/84'/0'/0'/1/5. Searches spanning more than
+// findPathProgress indices log their progress.
+func (w *Wallet) FindPath(addr btcutil.Address, maxIndex uint32) (string, error) {
+	if maxIndex >= hdkeychain.HardenedKeyStart {
+		return "", fmt.Errorf("invalid max index %d: must be below %d", maxIndex, uint32(hdkeychain.HardenedKeyStart))
+	}
+
+	if !addr.IsForNet(w.Params) {
+		return "", fmt.Errorf("address %s is not a %s address", addr.EncodeAddress(), w.Params.Name)
+	}
+
+	var t AddressType
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		t = AddressP2PKH
+	case *btcutil.AddressScriptHash:
+		t = AddressP2WPKHInP2SH
+	case *btcutil.AddressWitnessPubKeyHash:
+		t = AddressP2WPKH
+	case *btcutil.AddressTaproot:
+		t = AddressTaproot
+	default:
+		return "", fmt.Errorf("address %s is not of a type with a standard derivation path", addr.EncodeAddress())
+	}
+
+	expected := addr.EncodeAddress()
+	for change := uint32(0); change <= 1; change++ {
+		for index := uint32(0); index <= maxIndex; index++ {
+			address, err := w.DeriveAddress(t, change, index)
+			if err != nil {
+				return "", fmt.Errorf("error deriving %s address: %w", t, err)
+			}
+
+			if address.EncodeAddress() == expected {
+				return w.DerivationPath(t.Purpose(), change, index), nil
+			}
+
+			if (index+1)%findPathProgress == 0 {
+				slog.Info("searching address", "type", t.Name(), "chain", ChainName(change), "index", index+1, "max_index", maxIndex)
+			}
+		}
+	}
+
+	return "", fmt.Errorf("address %s not found in the first %d receive and change indices", expected, uint64(maxIndex)+1)
+}
+
+// MissingWordPlaceholder marks the unknown word of a mnemonic to complete
+const MissingWordPlaceholder = "?"
+
+// MissingWordCandidates replaces the single placeholder word of the mnemonic
+// with every word of the list and returns the mnemonics with a valid
+// checksum. The search space is bounded to the 2048 words of one position;
+// progress, if not nil, is called after each block of words.
+func MissingWordCandidates(mnemonic string, progress func(done int, total int)) ([]string, error) {
+	words := strings.Fields(normalizeMnemonic(mnemonic))
+
+	position := -1
+	for i, word := range words {
+		if word != MissingWordPlaceholder {
+			continue
+		}
+
+		if position >= 0 {
+			return nil, fmt.Errorf("only a single %q placeholder is supported", MissingWordPlaceholder)
+		}
+		position = i
+	}
+
+	if position < 0 {
+		return nil, fmt.Errorf("mnemonic has no %q placeholder", MissingWordPlaceholder)
+	}
+
+	wordList := bip39.GetWordList()
+
+	var candidates []string
+	for i, word := range wordList {
+		words[position] = word
+
+		candidate := strings.Join(words, " ")
+		if bip39.IsMnemonicValid(candidate) {
+			candidates = append(candidates, candidate)
+		}
+
+		if progress != nil && ((i+1)%256 == 0 || i+1 == len(wordList)) {
+			progress(i+1, len(wordList))
+		}
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// confusableWords are BIP-39 words that are easily misread when handwritten,
+// mostly pairs differing in a single similar looking letter
+var confusableWords = []string{
+	"cake", "lake", "cave", "have",
+	"fault", "vault", "wife", "wine", "wire", "wise", "wide", "wild",
+	"mail", "rail", "sail", "tail",
+	"mad", "man", "van", "fan",
+	"own", "owner", "cousin", "cushion",
+	"able", "cable", "table",
+}
+
+// mnemonicPredicates are the conditions selectable with -retry-until
+var mnemonicPredicates = map[string]func(blocklist []string) func(mnemonic string) bool{
+	"unambiguous": func(blocklist []string) func(mnemonic string) bool {
+		blocked := make(map[string]bool, len(blocklist))
+		for _, word := range blocklist {
+			blocked[word] = true
+		}
+
+		return func(mnemonic string) bool {
+			for _, word := range strings.Fields(mnemonic) {
+				if blocked[word] {
+					return false
+				}
+			}
+
+			return true
+		}
+	},
+}
+
+// NewWalletUntil generates mnemonics until one satisfies accept, giving up
+// after maxAttempts, and derives the wallet of the accepted one only so the
+// rejected candidates cost no PBKDF2 rounds. It returns the wallet and the
+// attempts taken. Rejecting mnemonics lowers the entropy slightly, by log2 of
+// the expected attempts.
+func NewWalletUntil(bitSize int, passphrase string, params *chaincfg.Params, accept func(mnemonic string) bool, maxAttempts int) (*Wallet, int, error) {
+	for attempts := 1; attempts <= maxAttempts; attempts++ {
+		entropy, err := bip39.NewEntropy(bitSize)
+		if err != nil {
+			return nil, attempts, fmt.Errorf("error generating entropy: %w", err)
+		}
+
+		mnemonic, err := bip39.NewMnemonic(entropy)
+		if err != nil {
+			return nil, attempts, fmt.Errorf("error generating mnemonic: %w", err)
+		}
+
+		if accept(mnemonic) {
+			wallet, err := NewWalletFromEntropy(entropy, passphrase, params)
+			return wallet, attempts, err
+		}
+	}
+
+	return nil, maxAttempts, fmt.Errorf("no acceptable mnemonic after %d attempts", maxAttempts)
+}
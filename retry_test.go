@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestNewWalletUntil checks the wallet is derived from the mnemonic accept
+// approved after the rejected ones, and that giving up returns an error
+func TestNewWalletUntil(t *testing.T) {
+	var candidates []string
+	accept := func(mnemonic string) bool {
+		candidates = append(candidates, mnemonic)
+		return len(candidates) == 3
+	}
+
+	wallet, attempts, err := NewWalletUntil(128, "", &chaincfg.MainNetParams, accept, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 3 || len(candidates) != 3 {
+		t.Fatalf("%d attempts and %d candidates, expected 3", attempts, len(candidates))
+	}
+
+	if wallet.Mnemonic != candidates[2] {
+		t.Errorf("wallet mnemonic %q, expected the accepted %q", wallet.Mnemonic, candidates[2])
+	}
+
+	restored, err := NewWalletFromMnemonic(candidates[2], "", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.MasterKey.String() != wallet.MasterKey.String() {
+		t.Error("wallet master key differs from the accepted mnemonic")
+	}
+
+	if _, attempts, err := NewWalletUntil(128, "", &chaincfg.MainNetParams, func(string) bool { return false }, 4); err == nil || attempts != 4 {
+		t.Errorf("rejecting every mnemonic returned error %v after %d attempts, expected an error after 4", err, attempts)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !insecureseed
+
+package main
+
+import (
+	"flag"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// newSeed derives the BIP-39 seed with the standard 2048 PBKDF2 iterations.
+// Builds with the insecureseed tag replace it, see seed_insecure.go.
+func newSeed(mnemonic string, passphrase string) []byte {
+	return bip39.NewSeed(mnemonic, passphrase)
+}
+
+// registerSeedFlags adds no flags, the iterations are fixed in normal builds
+func registerSeedFlags(fs *flag.FlagSet) {}
@@ -0,0 +1,32 @@
+//go:build insecureseed
+
+package main
+
+import (
+	"crypto/sha512"
+	"flag"
+	"log/slog"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// seedIterations is the PBKDF2 iteration count of the seed derivation. It
+// defaults to the 2048 required by BIP-39 and can only be lowered in builds
+// with the insecureseed tag, which must never be used for releases.
+var seedIterations = 2048
+
+// registerSeedFlags adds the iteration override to the flags of a command
+func registerSeedFlags(fs *flag.FlagSet) {
+	fs.IntVar(&seedIterations, "seed-passphrase-iterations", seedIterations,
+		"NON-STANDARD: PBKDF2 iterations of the seed derivation, for tests only. Seeds are incompatible with every other wallet")
+}
+
+// newSeed derives the BIP-39 seed with seedIterations PBKDF2 rounds so test
+// suites generating many wallets run faster
+func newSeed(mnemonic string, passphrase string) []byte {
+	if seedIterations != 2048 {
+		slog.Warn("deriving seed with non-standard PBKDF2 iterations, the wallet is incompatible with BIP-39", "iterations", seedIterations)
+	}
+
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), seedIterations, 64, sha512.New)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// Values of -seed-format, the secret written for each wallet
+const (
+	SeedFormatMnemonic   = "mnemonic"
+	SeedFormatHex        = "hex"
+	SeedFormatEntropyHex = "entropy-hex"
+	SeedFormatAezeed     = "aezeed"
+)
+
+var seedFormats = []string{SeedFormatMnemonic, SeedFormatHex, SeedFormatEntropyHex, SeedFormatAezeed}
+
+// ValidateSeedFormat checks the -seed-format value
+func ValidateSeedFormat(format string) error {
+	if !slices.Contains(seedFormats, format) {
+		return fmt.Errorf("invalid seed format %q: must be mnemonic, hex, entropy-hex or aezeed", format)
+	}
+
+	return nil
+}
+
+// SeedSecret returns the secret of the wallet in the format: the mnemonic,
+// the hex 64 byte BIP-39 seed, which also depends on the passphrase, the hex
+// entropy the mnemonic encodes, or the words of an aezeed wallet
+func (w *Wallet) SeedSecret(format string) (string, error) {
+	switch format {
+	case SeedFormatMnemonic:
+		return w.Mnemonic, nil
+	case SeedFormatAezeed:
+		// lnd uses the aezeed entropy as the BIP-32 seed, no aezeed restores
+		// the keys of a BIP-39 seed
+		if w.Aezeed == nil {
+			return "", fmt.Errorf("a BIP-39 wallet cannot be written as an aezeed")
+		}
+		return w.Mnemonic, nil
+	case SeedFormatHex:
+		return hex.EncodeToString(w.Seed), nil
+	case SeedFormatEntropyHex:
+		return hex.EncodeToString(w.Entropy), nil
+	default:
+		return "", ValidateSeedFormat(format)
+	}
+}
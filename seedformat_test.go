@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// TestSeedFormats restores the wallet from each -seed-format secret and
+// checks it yields the same master key, and that it cannot be an aezeed
+func TestSeedFormats(t *testing.T) {
+	wallet := testWallet(t)
+
+	for _, format := range seedFormats {
+		// aezeed wallets have keys of their own, see TestAezeedRoundTrip
+		if format == SeedFormatAezeed {
+			continue
+		}
+
+		secret, err := wallet.SeedSecret(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var restored *hdkeychain.ExtendedKey
+		switch format {
+		case SeedFormatMnemonic:
+			w, err := NewWalletFromMnemonic(secret, "", wallet.Params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored = w.MasterKey
+		case SeedFormatHex:
+			seed, err := hex.DecodeString(secret)
+			if err != nil {
+				t.Fatalf("invalid seed: %v", err)
+			}
+
+			restored, err = hdkeychain.NewMaster(seed, wallet.Params)
+			if err != nil {
+				t.Fatal(err)
+			}
+		case SeedFormatEntropyHex:
+			entropy, err := hex.DecodeString(secret)
+			if err != nil {
+				t.Fatalf("invalid entropy: %v", err)
+			}
+
+			w, err := NewWalletFromEntropy(entropy, "", wallet.Params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored = w.MasterKey
+		}
+
+		if restored.String() != wallet.MasterKey.String() {
+			t.Fatalf("%s secret %s restores a different master key", format, secret)
+		}
+	}
+
+	if _, err := wallet.SeedSecret(SeedFormatAezeed); err == nil {
+		t.Fatalf("BIP-39 wallet written as an aezeed")
+	}
+
+	if _, err := wallet.SeedSecret("electrum"); err == nil {
+		t.Fatalf("unknown seed format accepted")
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// AddressSetHash returns the SHA-256 of the receive addresses of the types at
+// indices 0 through count-1, each followed by a newline, type by type. Two
+// wallets or configurations agree on the hash exactly when they derive the
+// same addresses, without comparing the full lists.
+func (w *Wallet) AddressSetHash(types []AddressType, count uint32) ([]byte, error) {
+	hash := sha256.New()
+	for _, t := range types {
+		for index := uint32(0); index < count; index++ {
+			address, err := w.DeriveAddress(t, 0, index)
+			if err != nil {
+				return nil, fmt.Errorf("error deriving %s address at index %d: %w", t, index, err)
+			}
+
+			hash.Write([]byte(address.EncodeAddress() + "\n"))
+		}
+	}
+
+	return hash.Sum(nil), nil
+}
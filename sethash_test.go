@@ -0,0 +1,38 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// bip86SetHash is the address set hash of the mnemonic over every type and
+// the first five receive addresses
+const bip86SetHash = "1ec48e7e1989b5ee1411f901d1b5fb0358d1599f24d4ba52b5c8c83fa41615cf"
+
+// TestSetHash checks that the address set hash is deterministic across
+// wallets restored from the same mnemonic and changes with the count
+func TestSetHash(t *testing.T) {
+	wallet := testWallet(t)
+
+	restored := testWallet(t)
+
+	for _, w := range []*Wallet{wallet, restored} {
+		hash, err := w.AddressSetHash(AddressTypes, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(hash) != bip86SetHash {
+			t.Fatalf("address set hash %x, expected %s", hash, bip86SetHash)
+		}
+	}
+
+	shorter, err := wallet.AddressSetHash(AddressTypes, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hex.EncodeToString(shorter) == bip86SetHash {
+		t.Fatalf("address set hash does not depend on the count")
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// SignTaprootMessage signs the 32 byte message with the key-path key of the
+// BIP-86 receive address at index. The derived private key is tweaked with
+// an empty script root, so the BIP-340 signature verifies against the output
+// key committed to in the address.
+func (w *Wallet) SignTaprootMessage(index uint32, msg [32]byte) ([]byte, error) {
+	key, err := w.ExtendMasterKey(86, 0, index)
+	if err != nil {
+		return nil, fmt.Errorf("error extending master key: %w", err)
+	}
+
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("error getting private key: %w", err)
+	}
+	defer privKey.Zero()
+
+	tweaked := txscript.TweakTaprootPrivKey(*privKey, nil)
+	defer tweaked.Zero()
+
+	signature, err := schnorr.Sign(tweaked, msg[:])
+	if err != nil {
+		return nil, fmt.Errorf("error signing message: %w", err)
+	}
+
+	return signature.Serialize(), nil
+}
+
+// VerifyTaprootSignature checks a BIP-340 signature of the message against the
+// output key of a Taproot address
+func VerifyTaprootSignature(address btcutil.Address, msg [32]byte, signature []byte) error {
+	taproot, ok := address.(*btcutil.AddressTaproot)
+	if !ok {
+		return fmt.Errorf("address %s is not a Taproot address", address.EncodeAddress())
+	}
+
+	pubKey, err := schnorr.ParsePubKey(taproot.WitnessProgram())
+	if err != nil {
+		return fmt.Errorf("error parsing output key: %w", err)
+	}
+
+	sig, err := schnorr.ParseSignature(signature)
+	if err != nil {
+		return fmt.Errorf("error parsing signature: %w", err)
+	}
+
+	if !sig.Verify(msg[:], pubKey) {
+		return fmt.Errorf("signature does not match address %s", address.EncodeAddress())
+	}
+
+	return nil
+}
+
+// ParseMessageHash decodes the hex encoded 32 byte message of a signature
+func ParseMessageHash(message string) ([32]byte, error) {
+	var msg [32]byte
+
+	decoded, err := hex.DecodeString(message)
+	if err != nil || len(decoded) != len(msg) {
+		return msg, fmt.Errorf("message must be 32 bytes of hex, got %q", message)
+	}
+	copy(msg[:], decoded)
+
+	return msg, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"crypto/sha256"
+	"testing"
+)
+
+// TestTaprootSignature signs with the tweaked key of the first Taproot
+// address, verifies the signature against the address and checks that a
+// different message is rejected
+func TestTaprootSignature(t *testing.T) {
+	wallet := testWallet(t)
+
+	msg := sha256.Sum256([]byte("btc-wallet test"))
+
+	signature, err := wallet.SignTaprootMessage(0, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := wallet.DeriveTaprootAddress(0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyTaprootSignature(address, msg, signature); err != nil {
+		t.Fatalf("Taproot signature round trip: %v", err)
+	}
+
+	msg[0] ^= 1
+	if err := VerifyTaprootSignature(address, msg, signature); err == nil {
+		t.Fatalf("Taproot signature verified for a different message")
+	}
+}
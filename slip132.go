@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// SLIP-132 extended public key versions advertising the script type of the
+// nested (ypub/upub) and native (zpub/vpub) SegWit accounts
+var (
+	ypubVersion = []byte{0x04, 0x9d, 0x7c, 0xb2}
+	zpubVersion = []byte{0x04, 0xb2, 0x47, 0x46}
+	upubVersion = []byte{0x04, 0x4a, 0x52, 0x62}
+	vpubVersion = []byte{0x04, 0x5f, 0x1c, 0xf6}
+)
+
+// SLIP132PublicVersion returns the SLIP-132 public key version of the address
+// type on the network, or false if the type has no dedicated version
+func SLIP132PublicVersion(t AddressType, params *chaincfg.Params) ([]byte, bool) {
+	switch params.Net {
+	case chaincfg.MainNetParams.Net:
+		switch t {
+		case AddressP2WPKHInP2SH:
+			return ypubVersion, true
+		case AddressP2WPKH:
+			return zpubVersion, true
+		}
+	case chaincfg.TestNet3Params.Net, chaincfg.RegressionNetParams.Net, chaincfg.SigNetParams.Net:
+		switch t {
+		case AddressP2WPKHInP2SH:
+			return upubVersion, true
+		case AddressP2WPKH:
+			return vpubVersion, true
+		}
+	}
+
+	return nil, false
+}
+
+// ParseExtendedPublicKey parses an extended public key of the network in the
+// standard xpub/tpub or the SLIP-132 ypub/zpub/upub/vpub encoding. SLIP-132
+// keys are re-encoded with the standard version and also return the address
+// type implied by their prefix, ok is false for standard keys.
+func ParseExtendedPublicKey(key string, params *chaincfg.Params) (*hdkeychain.ExtendedKey, AddressType, bool, error) {
+	parsed, err := hdkeychain.NewKeyFromString(key)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("invalid extended public key: %w", err)
+	}
+
+	if parsed.IsPrivate() {
+		return nil, 0, false, fmt.Errorf("expected an extended public key, got a private key")
+	}
+
+	if bytes.Equal(parsed.Version(), params.HDPublicKeyID[:]) {
+		return parsed, 0, false, nil
+	}
+
+	for _, t := range AddressTypes {
+		version, ok := SLIP132PublicVersion(t, params)
+		if !ok || !bytes.Equal(parsed.Version(), version) {
+			continue
+		}
+
+		standard, err := parsed.CloneWithVersion(params.HDPublicKeyID[:])
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("error re-encoding extended public key: %w", err)
+		}
+
+		return standard, t, true, nil
+	}
+
+	return nil, 0, false, fmt.Errorf("extended public key version %x is not known for network %s", parsed.Version(), params.Name)
+}
+
+// accountDepth is the depth of the m/purpose'/coin'/account' node every
+// supported purpose expects an imported extended public key at
+const accountDepth = 3
+
+// CheckAccountKey checks the extended public key sits at the account level,
+// a hardened child at depth 3. A key pasted from the wallet root or a chain
+// node derives valid looking addresses the wallet never uses.
+func CheckAccountKey(key *hdkeychain.ExtendedKey) error {
+	if depth := key.Depth(); depth != accountDepth {
+		return fmt.Errorf("extended public key is at depth %d, expected an account key at depth %d such as m/84'/0'/0'", depth, accountDepth)
+	}
+
+	if !IsHardened(key.ChildIndex()) {
+		return fmt.Errorf("extended public key is the unhardened child %d, expected a hardened account index", key.ChildIndex())
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestAccountKey checks CheckAccountKey accepts the account zpub and rejects
+// the root and chain keys of the same wallet
+func TestAccountKey(t *testing.T) {
+	account, _, _, err := ParseExtendedPublicKey(bip84Zpub, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckAccountKey(account); err != nil {
+		t.Fatalf("account key rejected: %v", err)
+	}
+
+	root, err := testWallet(t).MasterKey.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain, err := account.Derive(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, key := range map[string]*hdkeychain.ExtendedKey{"root": root, "chain": chain} {
+		if err := CheckAccountKey(key); err == nil {
+			t.Errorf("%s key at depth %d accepted as an account key", name, key.Depth())
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// Split schemes of a mnemonic into two shares
+const (
+	// SplitXOR draws a random mnemonic as the first share and XORs its
+	// entropy into the second, as in Seed XOR. Both shares are valid
+	// mnemonics and either one alone reveals nothing about the wallet.
+	SplitXOR = "xor"
+
+	// SplitNaive cuts the word list in half. Each half still leaks about
+	// half of the entropy, so finding one half leaves a brute-forceable
+	// search for the other half on short mnemonics.
+	SplitNaive = "naive"
+)
+
+// NaiveSplitWarning is printed whenever naive shares are created
+const NaiveSplitWarning = "WARNING: each naive share reveals half of the mnemonic words and about half of its entropy. " +
+	"Anyone finding one share of a 12 word mnemonic is left with a search of about 64 bits. Prefer -scheme xor."
+
+// SplitMnemonic splits the mnemonic into two shares that restore it only
+// together, see SplitXOR and SplitNaive
+func SplitMnemonic(mnemonic string, scheme string) ([2]string, error) {
+	mnemonic = normalizeMnemonic(mnemonic)
+
+	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return [2]string{}, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	switch scheme {
+	case SplitXOR:
+		first := make([]byte, len(entropy))
+		if _, err := rand.Read(first); err != nil {
+			return [2]string{}, fmt.Errorf("error generating entropy: %w", err)
+		}
+
+		second := make([]byte, len(entropy))
+		for i := range entropy {
+			second[i] = entropy[i] ^ first[i]
+		}
+
+		var shares [2]string
+		for i, shareEntropy := range [][]byte{first, second} {
+			shares[i], err = bip39.NewMnemonic(shareEntropy)
+			if err != nil {
+				return [2]string{}, fmt.Errorf("error encoding share %d: %w", i+1, err)
+			}
+		}
+
+		return shares, nil
+	case SplitNaive:
+		words := strings.Fields(mnemonic)
+		half := len(words) / 2
+
+		return [2]string{strings.Join(words[:half], " "), strings.Join(words[half:], " ")}, nil
+	default:
+		return [2]string{}, fmt.Errorf("unknown split scheme %q: must be %s or %s", scheme, SplitXOR, SplitNaive)
+	}
+}
+
+// JoinMnemonic restores the mnemonic from the two shares of SplitMnemonic
+func JoinMnemonic(shares [2]string, scheme string) (string, error) {
+	switch scheme {
+	case SplitXOR:
+		var entropies [2][]byte
+		for i, share := range shares {
+			entropy, err := bip39.EntropyFromMnemonic(normalizeMnemonic(share))
+			if err != nil {
+				return "", fmt.Errorf("invalid share %d: %w", i+1, err)
+			}
+
+			entropies[i] = entropy
+		}
+
+		if len(entropies[0]) != len(entropies[1]) {
+			return "", fmt.Errorf("shares have different lengths, %d and %d bits", 8*len(entropies[0]), 8*len(entropies[1]))
+		}
+
+		entropy := make([]byte, len(entropies[0]))
+		for i := range entropy {
+			entropy[i] = entropies[0][i] ^ entropies[1][i]
+		}
+
+		return bip39.NewMnemonic(entropy)
+	case SplitNaive:
+		mnemonic := normalizeMnemonic(shares[0] + " " + shares[1])
+		if _, err := bip39.EntropyFromMnemonic(mnemonic); err != nil {
+			return "", fmt.Errorf("shares do not form a valid mnemonic, check their order: %w", err)
+		}
+
+		return mnemonic, nil
+	default:
+		return "", fmt.Errorf("unknown split scheme %q: must be %s or %s", scheme, SplitXOR, SplitNaive)
+	}
+}
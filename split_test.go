@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// TestSplit checks that both split schemes join back to a fresh mnemonic
+// and that the XOR shares differ from it
+func TestSplit(t *testing.T) {
+	entropy, err := bip39.NewEntropy(128)
+	if err != nil {
+		t.Fatalf("error generating entropy: %v", err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		t.Fatalf("error generating mnemonic: %v", err)
+	}
+
+	for _, scheme := range []string{SplitXOR, SplitNaive} {
+		shares, err := SplitMnemonic(mnemonic, scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if scheme == SplitXOR && (shares[0] == mnemonic || shares[1] == mnemonic) {
+			t.Fatalf("%s split: a share equals the mnemonic", scheme)
+		}
+
+		joined, err := JoinMnemonic(shares, scheme)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if joined != mnemonic {
+			t.Fatalf("%s split: joined mnemonic differs from the original", scheme)
+		}
+	}
+}
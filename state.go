@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// WalletState records the next unused address index per type of a wallet so
+// that consecutive runs keep issuing fresh addresses
+type WalletState struct {
+	Fingerprint string            `json:"fingerprint"`
+	NextIndex   map[string]uint32 `json:"next_index"`
+}
+
+// LoadState reads the state file at path, returning an empty state if the
+// file does not exist yet
+func LoadState(path string) (*WalletState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &WalletState{NextIndex: map[string]uint32{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading state file: %w", err)
+	}
+
+	var state WalletState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error parsing state file: %w", err)
+	}
+	if state.NextIndex == nil {
+		state.NextIndex = map[string]uint32{}
+	}
+
+	return &state, nil
+}
+
+// Save writes the state to path, replacing the previous file atomically
+func (s *WalletState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error replacing state file: %w", err)
+	}
+
+	return nil
+}
+
+// Bind checks that the state belongs to the wallet with the given
+// fingerprint, claiming a fresh state for it
+func (s *WalletState) Bind(fingerprint string) error {
+	if len(s.Fingerprint) == 0 {
+		s.Fingerprint = fingerprint
+		return nil
+	}
+
+	if s.Fingerprint != fingerprint {
+		return fmt.Errorf("state file belongs to wallet %s, not %s", s.Fingerprint, fingerprint)
+	}
+
+	return nil
+}
+
+// Start returns the first index to derive. All types advance together, so if
+// the stored indices differ the highest one is used to never reissue an address.
+func (s *WalletState) Start() uint32 {
+	var start uint32
+	for _, t := range AddressTypes {
+		if next := s.NextIndex[t.Name()]; next > start {
+			start = next
+		}
+	}
+
+	return start
+}
+
+// Advance records next as the next unused index of every type
+func (s *WalletState) Advance(next uint32) {
+	for _, t := range AddressTypes {
+		s.NextIndex[t.Name()] = next
+	}
+}
+
+// Range returns the first index of a run of count addresses and the index
+// following it, rejecting runs that would reach the hardened indices
+func (s *WalletState) Range(count int) (start, next uint32, err error) {
+	start = s.Start()
+	end := uint64(start) + uint64(count)
+	if count < 0 || end >= hdkeychain.HardenedKeyStart {
+		return 0, 0, fmt.Errorf("state file is at index %d, %d more addresses would reach the hardened index %d", start, count, uint32(hdkeychain.HardenedKeyStart))
+	}
+
+	return start, uint32(end), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+)
+
+// TestLoadStateMissing checks a missing state file starts at index 0
+func TestLoadStateMissing(t *testing.T) {
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if start := state.Start(); start != 0 {
+		t.Errorf("start %d, expected 0", start)
+	}
+}
+
+// TestStateRoundTrip checks an advanced state is saved with mode 0600 and
+// loads back at the same index and fingerprint
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.Bind("73c5da0a"); err != nil {
+		t.Fatal(err)
+	}
+	state.Advance(5)
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("mode %v, expected 0600", mode)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Fingerprint != "73c5da0a" {
+		t.Errorf("fingerprint %s, expected 73c5da0a", loaded.Fingerprint)
+	}
+	for _, typ := range AddressTypes {
+		if next := loaded.NextIndex[typ.Name()]; next != 5 {
+			t.Errorf("%s at %d, expected 5", typ.Name(), next)
+		}
+	}
+}
+
+// TestStateBindMismatch checks a state file refuses another wallet
+func TestStateBindMismatch(t *testing.T) {
+	state := &WalletState{Fingerprint: "00000000", NextIndex: map[string]uint32{}}
+
+	err := state.Bind("73c5da0a")
+	if err == nil || !strings.Contains(err.Error(), "belongs to wallet 00000000") {
+		t.Errorf("error %v, expected the state to belong to 00000000", err)
+	}
+}
+
+// TestStateRange checks a run of addresses may end just below the hardened
+// indices but not reach them
+func TestStateRange(t *testing.T) {
+	state := &WalletState{NextIndex: map[string]uint32{}}
+	state.Advance(hdkeychain.HardenedKeyStart - 10)
+
+	start, next, err := state.Range(9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != hdkeychain.HardenedKeyStart-10 || next != hdkeychain.HardenedKeyStart-1 {
+		t.Errorf("range %d to %d, expected %d to %d", start, next, hdkeychain.HardenedKeyStart-10, hdkeychain.HardenedKeyStart-1)
+	}
+
+	if _, _, err := state.Range(10); err == nil {
+		t.Error("accepted a run reaching the hardened indices")
+	}
+}
+
+// TestStateSavedAfterOutput checks the state only advances once the output
+// of the run is written
+func TestStateSavedAfterOutput(t *testing.T) {
+	discardLogs(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	args := []string{"-mnemonic", bip86Mnemonic, "-network", "testnet", "-state-file", path, "-addresses", "3", "-out", filepath.Join(dir, "out.txt")}
+
+	failing := append(args, "-paper", filepath.Join(dir, "missing", "paper.html"))
+	if err := runGenerate("restore", failing); err == nil {
+		t.Fatal("writing the paper wallet into a missing directory succeeded")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("state saved after a failed run: %v", err)
+	}
+
+	for _, want := range []uint32{3, 6} {
+		if err := runGenerate("restore", args); err != nil {
+			t.Fatal(err)
+		}
+
+		state, err := LoadState(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if start := state.Start(); start != want {
+			t.Errorf("start %d, expected %d", start, want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WalletStream writes wallets one at a time in the text, CSV or MessagePack
+// format, flushing after each so an interrupted run leaves every wallet
+// written so far intact
+type WalletStream struct {
+	w      io.Writer
+	format string
+	opts   OutputOptions
+	count  int
+}
+
+// NewWalletStream returns a stream writing to w in the format. JSON and table
+// output need the whole batch and cannot be streamed.
+func NewWalletStream(w io.Writer, format string, opts OutputOptions) (*WalletStream, error) {
+	switch format {
+	case "text", "csv", "msgpack":
+		return &WalletStream{w: w, format: format, opts: opts}, nil
+	default:
+		return nil, fmt.Errorf("format %s cannot be streamed, use text, csv or msgpack", format)
+	}
+}
+
+// Count returns the number of wallets written
+func (s *WalletStream) Count() int {
+	return s.count
+}
+
+// Write writes the next wallet, numbered after the wallets written before
+func (s *WalletStream) Write(wallet Generated) error {
+	s.count++
+
+	switch s.format {
+	case "text":
+		if s.count > 1 && s.opts.Primary == nil {
+			if _, err := io.WriteString(s.w, s.opts.Separator); err != nil {
+				return err
+			}
+		}
+
+		return writeText(s.w, []Generated{wallet}, s.opts)
+	case "csv":
+		writer := csv.NewWriter(s.w)
+
+		// The first wallet decides the descriptor columns, as in writeCSV
+		if s.count == 1 {
+			if err := writer.Write(csvHeader(s.opts, wallet.Descriptors)); err != nil {
+				return fmt.Errorf("error writing header: %w", err)
+			}
+		}
+
+		if err := writeCSVRows(writer, s.count+s.opts.NumberOffset, wallet, s.opts); err != nil {
+			return err
+		}
+
+		writer.Flush()
+
+		return writer.Error()
+	default:
+		records, err := walletRecords([]Generated{wallet}, s.opts)
+		if err != nil {
+			return err
+		}
+		records[0].Index = s.count + s.opts.NumberOffset
+
+		writer := bufio.NewWriter(s.w)
+		if err := writeMsgpackRecord(writer, records[0]); err != nil {
+			return err
+		}
+
+		return writer.Flush()
+	}
+}
+
+// validateContinuous checks the flags of -continuous: it replaces -count and
+// needs an -out file in a format that can be streamed
+func validateContinuous(countSet bool, out string, format string) error {
+	if countSet {
+		return fmt.Errorf("-continuous and -count are mutually exclusive")
+	}
+
+	if len(out) == 0 {
+		return fmt.Errorf("-continuous requires -out")
+	}
+
+	if len(format) > 0 {
+		if _, err := NewWalletStream(io.Discard, format, OutputOptions{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StreamUntilSignal writes the wallets returned by next for i = 0, 1, ... to
+// the stream until SIGINT or SIGTERM. The wallet being generated when the
+// signal arrives is still written, and file is synced after every wallet so
+// the output survives a crash. The first failure stops the stream.
+func StreamUntilSignal(file *os.File, stream *WalletStream, next func(i int) ([]Generated, []error)) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("generating wallets until interrupted", "file", file.Name())
+
+	for i := 0; ctx.Err() == nil; i++ {
+		wallets, failures := next(i)
+		if len(failures) > 0 {
+			return errors.Join(failures...)
+		}
+
+		for _, wallet := range wallets {
+			if err := stream.Write(wallet); err != nil {
+				return fmt.Errorf("error writing to file: %w", err)
+			}
+		}
+
+		if err := file.Sync(); err != nil {
+			return fmt.Errorf("error syncing file: %w", err)
+		}
+	}
+
+	slog.Info("stopped on signal", "wallets", stream.Count())
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// inputVSize is the virtual size in vbytes of a signed input of the type,
+// rounded up
+func inputVSize(t AddressType) int64 {
+	switch t {
+	case AddressP2PKH:
+		return 148
+	case AddressP2WPKHInP2SH:
+		return 91
+	case AddressP2WPKH:
+		return 68
+	default:
+		return 58
+	}
+}
+
+// txOverheadVSize covers the version, locktime, input and output counts and
+// the SegWit marker and flag
+const txOverheadVSize = 11
+
+// ScanUTXOs collects the unspent outputs of the receive and change chains of
+// every type. Each chain is scanned until its own gap of consecutive
+// addresses without history, receiveGap or changeGap, as wallets hand out
+// change addresses at a different pace than receive addresses.
+func (w *Wallet) ScanUTXOs(explorer UTXOExplorer, receiveGap int, changeGap int) ([]UTXO, error) {
+	gapLimits := [2]int{receiveGap, changeGap}
+	for change, gapLimit := range gapLimits {
+		if gapLimit < 1 {
+			return nil, fmt.Errorf("invalid %s gap %d: must be at least 1", strings.ToLower(ChainName(uint32(change))), gapLimit)
+		}
+	}
+
+	var utxos []UTXO
+	for _, t := range AddressTypes {
+		for change := uint32(0); change <= 1; change++ {
+			for index, gap := uint32(0), 0; gap < gapLimits[change]; index++ {
+				address, err := w.DeriveAddress(t, change, index)
+				if err != nil {
+					return nil, err
+				}
+
+				count, err := explorer.TxCount(address.EncodeAddress())
+				if err != nil {
+					return nil, err
+				}
+
+				if count == 0 {
+					gap++
+					continue
+				}
+				gap = 0
+
+				found, err := w.addressUTXOs(explorer, address, change, index)
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", t, w.DerivationPath(t.Purpose(), change, index), err)
+				}
+
+				utxos = append(utxos, found...)
+			}
+		}
+	}
+
+	return utxos, nil
+}
+
+// addressUTXOs returns the unspent outputs of the derived address at change
+// and index, with the previous transactions P2PKH inputs require
+func (w *Wallet) addressUTXOs(explorer UTXOExplorer, address btcutil.Address, change uint32, index uint32) ([]UTXO, error) {
+	pkScript, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, fmt.Errorf("error creating output script: %w", err)
+	}
+
+	entries, err := explorer.UTXOs(address.EncodeAddress())
+	if err != nil {
+		return nil, err
+	}
+
+	_, legacy := address.(*btcutil.AddressPubKeyHash)
+
+	utxos := make([]UTXO, 0, len(entries))
+	for _, entry := range entries {
+		hash, err := chainhash.NewHashFromStr(entry.Txid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %q: %w", entry.Txid, err)
+		}
+
+		utxo := UTXO{
+			OutPoint: wire.OutPoint{Hash: *hash, Index: entry.Vout},
+			Amount:   btcutil.Amount(entry.Value),
+			PkScript: pkScript,
+			Change:   change,
+			Index:    index,
+		}
+
+		if legacy {
+			utxo.PrevTx, err = explorer.RawTx(entry.Txid)
+			if err != nil {
+				return nil, err
+			}
+
+			if utxo.PrevTx.TxHash() != *hash || int(entry.Vout) >= len(utxo.PrevTx.TxOut) {
+				return nil, fmt.Errorf("explorer returned a transaction not matching %s", utxo.OutPoint)
+			}
+
+			if out := utxo.PrevTx.TxOut[entry.Vout]; out.Value != entry.Value || !bytes.Equal(out.PkScript, pkScript) {
+				return nil, fmt.Errorf("explorer returned a transaction not matching %s", utxo.OutPoint)
+			}
+		}
+
+		utxos = append(utxos, utxo)
+	}
+
+	return utxos, nil
+}
+
+// BuildSweep creates an unsigned PSBT consolidating the funds of every derived
+// address found through the explorer into a single output to destination,
+// paying feeRate satoshis per vbyte. The chains are scanned as in ScanUTXOs.
+// Outputs worth less than the fee of spending them are left behind with a
+// warning. Each input carries the derivation of its own type, see BuildPSBT.
+func (w *Wallet) BuildSweep(explorer UTXOExplorer, destination btcutil.Address, feeRate int, receiveGap int, changeGap int) (*psbt.Packet, error) {
+	if feeRate < 1 {
+		return nil, fmt.Errorf("invalid fee rate %d: must be at least 1 sat/vB", feeRate)
+	}
+
+	destinationScript, err := txscript.PayToAddrScript(destination)
+	if err != nil {
+		return nil, fmt.Errorf("error creating output script: %w", err)
+	}
+
+	found, err := w.ScanUTXOs(explorer, receiveGap, changeGap)
+	if err != nil {
+		return nil, err
+	}
+
+	// The output is the value, the script length and the script
+	vsize := int64(txOverheadVSize + 8 + 1 + len(destinationScript))
+
+	var utxos []UTXO
+	var total btcutil.Amount
+	for _, utxo := range found {
+		t, err := w.UTXOType(utxo)
+		if err != nil {
+			return nil, err
+		}
+
+		cost := btcutil.Amount(inputVSize(t) * int64(feeRate))
+		if utxo.Amount <= cost {
+			slog.Warn("skipping dust output worth less than its spending fee", "outpoint", utxo.OutPoint, "amount", utxo.Amount, "fee", cost)
+			continue
+		}
+
+		utxos = append(utxos, utxo)
+		total += utxo.Amount
+		vsize += inputVSize(t)
+	}
+
+	if len(utxos) == 0 {
+		return nil, fmt.Errorf("no spendable outputs found among %d unspent outputs", len(found))
+	}
+
+	fee := btcutil.Amount(vsize * int64(feeRate))
+	output := wire.NewTxOut(int64(total-fee), destinationScript)
+	if total <= fee || mempool.IsDust(output, mempool.DefaultMinRelayTxFee) {
+		return nil, fmt.Errorf("inputs of %v leave a dust output after the %v fee", total, fee)
+	}
+
+	slog.Info("building sweep", "inputs", len(utxos), "amount", btcutil.Amount(output.Value), "fee", fee, "vsize", vsize)
+
+	return w.BuildPSBT(utxos, []*wire.TxOut{output})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// sweepExplorer is an offline UTXOExplorer answering from maps of unspent
+// outputs and transactions
+type sweepExplorer struct {
+	utxos map[string][]ExplorerUTXO
+	txs   map[string]*wire.MsgTx
+}
+
+func (e sweepExplorer) TxCount(address string) (int, error) {
+	return len(e.utxos[address]), nil
+}
+
+func (e sweepExplorer) UTXOs(address string) ([]ExplorerUTXO, error) {
+	return e.utxos[address], nil
+}
+
+func (e sweepExplorer) RawTx(txid string) (*wire.MsgTx, error) {
+	tx, ok := e.txs[txid]
+	if !ok {
+		return nil, fmt.Errorf("unknown transaction %s", txid)
+	}
+
+	return tx, nil
+}
+
+// TestSweep sweeps P2PKH, P2WPKH and change P2TR outputs past a gap of
+// unused addresses plus one dust output, and checks the inputs, their
+// derivation metadata and the fee of the consolidated output
+func TestSweep(t *testing.T) {
+	wallet := testWallet(t)
+
+	type funded struct {
+		typ    AddressType
+		change uint32
+		index  uint32
+		amount int64
+	}
+
+	outputs := []funded{
+		{AddressP2PKH, 0, 0, 50000},
+		{AddressP2WPKH, 0, 2, 30000},
+		{AddressP2WPKH, 0, 3, 50},
+		{AddressTaproot, 1, 0, 20000},
+	}
+
+	explorer := sweepExplorer{utxos: make(map[string][]ExplorerUTXO), txs: make(map[string]*wire.MsgTx)}
+	for i, o := range outputs {
+		address, err := wallet.DeriveAddress(o.typ, o.change, o.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		script, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A distinct funding transaction per output, with the P2PKH one
+		// served as the previous transaction
+		tx := wire.NewMsgTx(wire.TxVersion)
+		tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: uint32(i)}, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(o.amount, script))
+
+		txid := tx.TxHash().String()
+		explorer.txs[txid] = tx
+		explorer.utxos[address.EncodeAddress()] = []ExplorerUTXO{{Txid: txid, Vout: 0, Value: o.amount}}
+	}
+
+	destination, err := btcutil.DecodeAddress(bip84Address, wallet.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The skipped dust output is expected, keep its warning out of the
+	// test output
+	discardLogs(t)
+
+	packet, err := wallet.BuildSweep(explorer, destination, 2, 5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(packet.Inputs) != 3 {
+		t.Fatalf("sweep has %d inputs, expected 3 without the dust output", len(packet.Inputs))
+	}
+
+	// 11 vB overhead, 31 vB P2WPKH output and one input of each type
+	fee := int64(11+31+148+68+58) * 2
+	if len(packet.UnsignedTx.TxOut) != 1 || packet.UnsignedTx.TxOut[0].Value != 100000-fee {
+		t.Fatalf("sweep output %v, expected a single output of %d", packet.UnsignedTx.TxOut, 100000-fee)
+	}
+
+	for i, input := range packet.Inputs {
+		switch {
+		case input.NonWitnessUtxo != nil && len(input.Bip32Derivation) == 1:
+		case input.WitnessUtxo != nil && len(input.Bip32Derivation) == 1:
+		case input.WitnessUtxo != nil && len(input.TaprootBip32Derivation) == 1:
+		default:
+			t.Fatalf("sweep input %d lacks its previous output or key derivation", i)
+		}
+	}
+}
+
+// TestGapLimits funds receive index 4 and change index 1 and checks that
+// each chain's scan stops at its own gap
+func TestGapLimits(t *testing.T) {
+	wallet := testWallet(t)
+
+	explorer := sweepExplorer{utxos: make(map[string][]ExplorerUTXO)}
+	for i, location := range [][2]uint32{{0, 4}, {1, 1}} {
+		address, err := wallet.DeriveAddress(AddressP2WPKH, location[0], location[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		txid := strings.Repeat(fmt.Sprint(i+1), 64)
+		explorer.utxos[address.EncodeAddress()] = []ExplorerUTXO{{Txid: txid, Vout: 0, Value: 10000}}
+	}
+
+	// Receive and change gaps and the UTXOs they reach
+	cases := []struct {
+		receiveGap, changeGap int
+		expected              []uint32
+	}{
+		{5, 2, []uint32{0, 1}},
+		{5, 1, []uint32{0}},
+		{4, 2, []uint32{1}},
+	}
+
+	for _, c := range cases {
+		utxos, err := wallet.ScanUTXOs(explorer, c.receiveGap, c.changeGap)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var chains []uint32
+		for _, utxo := range utxos {
+			chains = append(chains, utxo.Change)
+		}
+
+		if fmt.Sprint(chains) != fmt.Sprint(c.expected) {
+			t.Fatalf("scan with receive gap %d and change gap %d found chains %v, expected %v", c.receiveGap, c.changeGap, chains, c.expected)
+		}
+	}
+}
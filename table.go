@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// defaultTableWidth is assumed when the terminal width is unknown
+const defaultTableWidth = 80
+
+// TableWidth returns the width -format table fits its mnemonic column into:
+// the width of the terminal on stdout, else $COLUMNS, else 80 columns
+func TableWidth() int {
+	if columns := terminalColumns(os.Stdout); columns > 0 {
+		return columns
+	}
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	return defaultTableWidth
+}
+
+// truncate shortens s to at most width runes, ending it with an ellipsis
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	if width < 1 {
+		return "…"
+	}
+
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+// tableRow is a row of -format table output
+type tableRow []string
+
+// writeTable writes one aligned row per address, with the mnemonic on the
+// first row of each wallet. Unless opts.TableWidth is 0 the mnemonic column
+// is truncated so rows fit that width.
+func writeTable(w io.Writer, wallets []Generated, opts OutputOptions) error {
+	header := tableRow{"#", "Index", "Type", "Address", opts.secretLabel()}
+	if opts.Change {
+		header = append(header[:2], append(tableRow{"Chain"}, header[2:]...)...)
+	}
+
+	rows := []tableRow{header}
+	for i, wallet := range wallets {
+		for j, set := range wallet.AddressSets() {
+			for k, t := range AddressTypes {
+				mnemonic := ""
+				if j == 0 && k == 0 {
+					mnemonic = opts.formatSecret(wallet)
+				}
+
+				row := tableRow{strconv.Itoa(i + 1 + opts.NumberOffset), strconv.FormatUint(uint64(set.Index), 10), t.Name(), opts.formatAddress(set.Address(t)), mnemonic}
+				if opts.Change {
+					row = append(row[:2], append(tableRow{ChainName(set.Change)}, row[2:]...)...)
+				}
+
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	if opts.TableWidth > 0 {
+		// The other columns are never truncated, the mnemonic gets the rest
+		// of the width after their widths and the two space padding
+		used := 0
+		for col := 0; col < len(header)-1; col++ {
+			widest := 0
+			for _, row := range rows {
+				widest = max(widest, utf8.RuneCountInString(row[col]))
+			}
+			used += widest + 2
+		}
+
+		available := max(opts.TableWidth-used, len(header[len(header)-1]))
+
+		truncated := false
+		for _, row := range rows[1:] {
+			mnemonic := row[len(row)-1]
+			row[len(row)-1] = truncate(mnemonic, available)
+			truncated = truncated || row[len(row)-1] != mnemonic
+		}
+
+		// A new wallet whose mnemonic is only shown truncated is lost
+		if truncated {
+			slog.Warn("mnemonics are truncated to the terminal width, add -full to show them")
+		}
+	}
+
+	var aligned strings.Builder
+	tw := tabwriter.NewWriter(&aligned, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	// Rows without a mnemonic end in the padding of the address column
+	for _, line := range strings.SplitAfter(aligned.String(), "\n") {
+		if _, err := io.WriteString(w, strings.TrimRight(line, " \n")+strings.Repeat("\n", strings.Count(line, "\n"))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
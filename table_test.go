@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestTable checks that table output aligns the address column, truncates
+// the mnemonic to the width with an ellipsis and keeps it in full without one
+func TestTable(t *testing.T) {
+	wallet := testWallet(t)
+
+	set, err := wallet.DeriveAll(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wallets := []Generated{{
+		P2pkhAddress:      set.P2pkhAddress,
+		P2wpkhP2shAddress: set.P2wpkhP2shAddress,
+		P2wpkhAddress:     set.P2wpkhAddress,
+		TaprootAddress:    set.TaprootAddress,
+		Mnemonic:          wallet.Mnemonic,
+	}}
+
+	// The truncation warning is expected
+	discardLogs(t)
+
+	for _, width := range []int{100, 0} {
+		var out bytes.Buffer
+		if err := WriteWallets(&out, "table", wallets, OutputOptions{TableWidth: width}); err != nil {
+			t.Fatal(err)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		if len(lines) != 1+len(AddressTypes) {
+			t.Fatalf("table has %d lines, expected %d", len(lines), 1+len(AddressTypes))
+		}
+
+		column := strings.Index(lines[0], "Address")
+		for i, typ := range AddressTypes {
+			if strings.Index(lines[i+1], set.Address(typ).EncodeAddress()) != column {
+				t.Fatalf("table %s address is not aligned with its header", typ)
+			}
+		}
+
+		full := strings.HasSuffix(lines[1], bip86Mnemonic)
+		if width > 0 && (full || !strings.HasSuffix(lines[1], "…") || utf8.RuneCountInString(lines[1]) > width) {
+			t.Fatalf("table row %q is not truncated to %d columns", lines[1], width)
+		}
+
+		if width == 0 && !full {
+			t.Fatalf("table row %q does not show the full mnemonic", lines[1])
+		}
+	}
+}
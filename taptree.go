@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// TaprootScriptRoot returns the BIP-341 merkle root of the script tree that
+// txscript.AssembleTaprootScriptTree builds from the tapscript leaves: pairs
+// of leaves in order become TapBranch nodes, an odd last leaf joins the last
+// branch. Spending through a leaf needs the whole list of leaves in this
+// order to rebuild its control block.
+func TaprootScriptRoot(leaves [][]byte) ([]byte, error) {
+	if len(leaves) == 0 {
+		return nil, fmt.Errorf("script tree has no leaves")
+	}
+
+	tapLeaves := make([]txscript.TapLeaf, 0, len(leaves))
+	seen := make(map[string]int)
+	for i, script := range leaves {
+		if len(script) == 0 {
+			return nil, fmt.Errorf("leaf %d has an empty script", i+1)
+		}
+
+		// txscript indexes the leaves by hash, a repeated leaf would get
+		// the wrong inclusion proof
+		if first, ok := seen[string(script)]; ok {
+			return nil, fmt.Errorf("leaf %d repeats the script of leaf %d", i+1, first+1)
+		}
+		seen[string(script)] = i
+
+		tapLeaves = append(tapLeaves, txscript.NewBaseTapLeaf(script))
+	}
+
+	root := txscript.AssembleTaprootScriptTree(tapLeaves...).RootNode.TapHash()
+	return root[:], nil
+}
+
+// DeriveTaprootTreeAddress derives the Taproot address at index of the change
+// chain committing to a script tree of the leaves, see TaprootScriptRoot.
+// The internal key of the BIP-86 path keeps the key path spendable by the
+// wallet, but other BIP-86 wallets only find the address without a tree.
+func (w *Wallet) DeriveTaprootTreeAddress(change uint32, index uint32, leaves [][]byte) (btcutil.Address, error) {
+	root, err := TaprootScriptRoot(leaves)
+	if err != nil {
+		return nil, err
+	}
+
+	addressIndex, err := w.ExtendMasterKey(86, change, index)
+	if err != nil {
+		return nil, fmt.Errorf("error extending master key: %w", err)
+	}
+
+	internalKey, err := addressIndex.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("error getting public key: %w", err)
+	}
+
+	outputKey, err := xOnlyOutputKey(txscript.ComputeTaprootOutputKey(internalKey, root))
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := btcutil.NewAddressTaproot(outputKey, w.Params)
+	if err != nil {
+		return nil, fmt.Errorf("error generating Taproot address: %w", err)
+	}
+
+	return address, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// TestTaprootTree checks the merkle roots of two and three leaf trees of the
+// scripts OP_1, OP_2 and OP_3, computed by hand from the BIP-341 TapLeaf and
+// TapBranch tagged hashes, and that a tree address differs from the key path
+// only address while rejecting repeated leaves
+func TestTaprootTree(t *testing.T) {
+	wallet := testWallet(t)
+
+	leaves := [][]byte{{txscript.OP_1}, {txscript.OP_2}, {txscript.OP_3}}
+
+	for n, expected := range map[int]string{
+		2: "6496f0779f38b871013be71ee7dcce8fcdcc02afc4c688acb159fc5de2fba55e",
+		3: "609f09890e4348cc5bcc26e51c432c7830162715d64aab7c306f853c9da06a7b",
+	} {
+		root, err := TaprootScriptRoot(leaves[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(root) != expected {
+			t.Fatalf("%d leaf merkle root %x, expected %s", n, root, expected)
+		}
+	}
+
+	treeAddress, err := wallet.DeriveTaprootTreeAddress(0, 0, leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyPathAddress, err := wallet.DeriveAddress(AddressTaproot, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if treeAddress.EncodeAddress() == keyPathAddress.EncodeAddress() {
+		t.Fatalf("script tree address %s equals the key path address", treeAddress.EncodeAddress())
+	}
+
+	// Rebuild the output key by hand from the tweak committing to the root
+	key, err := wallet.ExtendMasterKey(86, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	internalKey, err := key.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := TaprootScriptRoot(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tweak := chainhash.TaggedHash(chainhash.TagTapTweak, schnorr.SerializePubKey(internalKey), root)
+	outputKey, err := ApplyTaprootTweak(internalKey, tweak[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(treeAddress.ScriptAddress(), schnorr.SerializePubKey(outputKey)) {
+		t.Fatalf("script tree address %s does not commit to the merkle root", treeAddress.EncodeAddress())
+	}
+
+	if _, err := TaprootScriptRoot([][]byte{{txscript.OP_1}, {txscript.OP_1}}); err == nil {
+		t.Fatalf("repeated tapleaf accepted")
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// TaprootTweak returns the BIP-341 key-path tweak of the internal key without
+// a script tree: the TapTweak tagged hash of its x-only serialization, as
+// used by txscript.ComputeTaprootKeyNoScript
+func TaprootTweak(internalKey *btcec.PublicKey) []byte {
+	tweak := chainhash.TaggedHash(chainhash.TagTapTweak, schnorr.SerializePubKey(internalKey))
+	return tweak[:]
+}
+
+// ApplyTaprootTweak computes the output key internalKey + tweak*G from the
+// x-only internal key, reconstructing the output key by hand
+func ApplyTaprootTweak(internalKey *btcec.PublicKey, tweak []byte) (*btcec.PublicKey, error) {
+	// The x-only key stands for the point with the even y coordinate
+	even, err := schnorr.ParsePubKey(schnorr.SerializePubKey(internalKey))
+	if err != nil {
+		return nil, fmt.Errorf("error lifting internal key: %w", err)
+	}
+
+	var scalar btcec.ModNScalar
+	if overflow := scalar.SetByteSlice(tweak); overflow {
+		return nil, fmt.Errorf("tweak exceeds the curve order")
+	}
+
+	var point, tweakPoint, output btcec.JacobianPoint
+	even.AsJacobian(&point)
+	btcec.ScalarBaseMultNonConst(&scalar, &tweakPoint)
+	btcec.AddNonConst(&point, &tweakPoint, &output)
+	output.ToAffine()
+
+	return btcec.NewPublicKey(&output.X, &output.Y), nil
+}
+
+// xOnlyOutputKey serializes the tweaked output key to the 32 byte x-only form
+// of a Taproot address, first checking it is a valid point whose x-only form
+// parses back to it. A bad tweak would otherwise yield an address whose
+// funds no key can spend.
+func xOnlyOutputKey(outputKey *btcec.PublicKey) ([]byte, error) {
+	if !outputKey.IsOnCurve() {
+		return nil, fmt.Errorf("taproot output key is not a valid curve point")
+	}
+
+	xOnly := schnorr.SerializePubKey(outputKey)
+
+	parsed, err := schnorr.ParsePubKey(xOnly)
+	if err != nil {
+		return nil, fmt.Errorf("taproot output key %x does not parse back: %w", xOnly, err)
+	}
+
+	if parsed.X().Cmp(outputKey.X()) != 0 {
+		return nil, fmt.Errorf("taproot output key %x parses back to a different point", xOnly)
+	}
+
+	return xOnly, nil
+}
+
+// TapTweak returns the tweak of the Taproot address at index of the change chain
+func (w *Wallet) TapTweak(change uint32, index uint32) ([]byte, error) {
+	key, err := w.ExtendMasterKey(86, change, index)
+	if err != nil {
+		return nil, fmt.Errorf("error extending master key: %w", err)
+	}
+
+	internalKey, err := key.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("error getting public key: %w", err)
+	}
+
+	return TaprootTweak(internalKey), nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// TestTapTweak checks that applying the exposed tweak to the internal key by
+// hand reproduces the output key of each BIP-86 vector
+func TestTapTweak(t *testing.T) {
+	wallet := testWallet(t)
+
+	for _, v := range bip86Vectors {
+		key, err := wallet.ExtendMasterKey(86, v.change, v.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		internalKey, err := key.ECPubKey()
+		if err != nil {
+			t.Fatalf("error getting public key: %v", err)
+		}
+
+		tweak, err := wallet.TapTweak(v.change, v.index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		outputKey, err := ApplyTaprootTweak(internalKey, tweak)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := hex.EncodeToString(schnorr.SerializePubKey(outputKey)); got != v.outputKey {
+			t.Fatalf("BIP-86 %s: tweaked output key %s, expected %s", wallet.DerivationPath(86, v.change, v.index), got, v.outputKey)
+		}
+
+		// The guard of DeriveTaprootAddress passes every valid derivation
+		if xOnly, err := xOnlyOutputKey(outputKey); err != nil || hex.EncodeToString(xOnly) != v.outputKey {
+			t.Fatalf("BIP-86 %s: output key rejected by the x-only check: %v", wallet.DerivationPath(86, v.change, v.index), err)
+		}
+	}
+
+	// x = y = 1 is not on the curve
+	var one btcec.FieldVal
+	one.SetInt(1)
+	if _, err := xOnlyOutputKey(btcec.NewPublicKey(&one, &one)); err == nil {
+		t.Fatalf("x-only check accepted a point off the curve")
+	}
+}
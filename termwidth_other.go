@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// terminalColumns returns 0, the terminal width is only detected on Unix
+func terminalColumns(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// TimelockScript builds the witness script locking funds to the public key
+// until locktime: <locktime> OP_CHECKLOCKTIMEVERIFY OP_DROP <pubkey>
+// OP_CHECKSIG. Like nLockTime, values below 500000000 are block heights and
+// higher values Unix timestamps.
+func TimelockScript(pubKey []byte, locktime uint32) ([]byte, error) {
+	if locktime == 0 {
+		return nil, fmt.Errorf("invalid locktime 0: the output would not be locked")
+	}
+
+	script, err := txscript.NewScriptBuilder().
+		AddInt64(int64(locktime)).
+		AddOp(txscript.OP_CHECKLOCKTIMEVERIFY).
+		AddOp(txscript.OP_DROP).
+		AddData(pubKey).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		return nil, fmt.Errorf("error building timelock script: %w", err)
+	}
+
+	return script, nil
+}
+
+// DeriveTimelockScript builds the timelock script over the key at index of
+// the BIP-84 receive chain, m/84'/0'/0'/0/index
+func (w *Wallet) DeriveTimelockScript(index uint32, locktime uint32) ([]byte, error) {
+	addressIndex, err := w.ExtendMasterKey(84, 0, index)
+	if err != nil {
+		return nil, fmt.Errorf("error extending master key: %w", err)
+	}
+
+	pubKey, err := addressIndex.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("error getting public key: %w", err)
+	}
+
+	return TimelockScript(pubKey.SerializeCompressed(), locktime)
+}
+
+// DeriveTimelockAddress derives the P2WSH address of the timelock script at
+// index, see DeriveTimelockScript. Spending needs the script as well as the
+// key, so it has to be backed up next to the mnemonic.
+func (w *Wallet) DeriveTimelockAddress(index uint32, locktime uint32) (btcutil.Address, error) {
+	script, err := w.DeriveTimelockScript(index, locktime)
+	if err != nil {
+		return nil, err
+	}
+
+	scriptHash := sha256.Sum256(script)
+	address, err := btcutil.NewAddressWitnessScriptHash(scriptHash[:], w.Params)
+	if err != nil {
+		return nil, fmt.Errorf("error generating P2WSH address: %w", err)
+	}
+
+	return address, nil
+}
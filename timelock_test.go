@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// TestTimelock checks the P2WSH timelock address commits to the CLTV script
+// over the BIP-84 key
+func TestTimelock(t *testing.T) {
+	wallet := testWallet(t)
+
+	const locktime = 840000
+
+	key, err := wallet.ExtendMasterKey(84, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	script, err := wallet.DeriveTimelockScript(0, locktime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 840000 is pushed as the 3 byte little-endian number 40d10c
+	expected := "0340d10cb17521" + hex.EncodeToString(pubKey.SerializeCompressed()) + "ac"
+	if hex.EncodeToString(script) != expected {
+		t.Fatalf("timelock script %x, expected %s", script, expected)
+	}
+
+	address, err := wallet.DeriveTimelockAddress(0, locktime)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scriptHash := sha256.Sum256(script)
+	if version, program, err := WitnessProgram(address); err != nil || version != 0 || program != hex.EncodeToString(scriptHash[:]) {
+		t.Fatalf("timelock address %s does not commit to the timelock script", address.EncodeAddress())
+	}
+
+	if _, err := wallet.DeriveTimelockAddress(0, 0); err == nil {
+		t.Fatalf("timelock address with locktime 0 was accepted")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TreeNode is a node of a rendered derivation tree
+type TreeNode struct {
+	Label    string
+	Children []*TreeNode
+}
+
+// DerivationTree returns the derivation of the addresses at indices of the
+// change chain as a tree: master, purpose', coin', account', change and the
+// index leaves annotated with their address, one branch per type
+func (w *Wallet) DerivationTree(types []AddressType, change uint32, indices []uint32) (*TreeNode, error) {
+	chainName := "receive"
+	if change != 0 {
+		chainName = "change"
+	}
+
+	root := &TreeNode{Label: "m"}
+	for _, t := range types {
+		chain := &TreeNode{Label: fmt.Sprintf("%d %s", change, chainName)}
+		for _, index := range indices {
+			address, err := w.DeriveAddress(t, change, index)
+			if err != nil {
+				return nil, fmt.Errorf("error deriving %s address at index %d: %w", t, index, err)
+			}
+
+			chain.Children = append(chain.Children, &TreeNode{Label: fmt.Sprintf("%d %s", index, address.EncodeAddress())})
+		}
+
+		account := &TreeNode{Label: "0' account", Children: []*TreeNode{chain}}
+		coin := &TreeNode{Label: fmt.Sprintf("%d' coin type", w.Params.HDCoinType), Children: []*TreeNode{account}}
+		purpose := &TreeNode{Label: fmt.Sprintf("%d' purpose (%s)", t.Purpose(), t), Children: []*TreeNode{coin}}
+
+		root.Children = append(root.Children, purpose)
+	}
+
+	return root, nil
+}
+
+// Render returns the tree drawn with ASCII branches like tree --charset ascii
+func (n *TreeNode) Render() string {
+	var b strings.Builder
+	fmt.Fprintln(&b, n.Label)
+	n.renderChildren(&b, "")
+
+	return b.String()
+}
+
+func (n *TreeNode) renderChildren(w io.Writer, prefix string) {
+	for i, child := range n.Children {
+		branch, indent := "|-- ", "|   "
+		if i == len(n.Children)-1 {
+			branch, indent = "`-- ", "    "
+		}
+
+		fmt.Fprintln(w, prefix+branch+child.Label)
+		child.renderChildren(w, prefix+indent)
+	}
+}
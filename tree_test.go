@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// bip86Tree is the rendered BIP-86 derivation of the first two receive
+// addresses of the BIP-86 test vector mnemonic
+const bip86Tree = "m\n" +
+	"`-- 86' purpose (BIP-86 P2TR)\n" +
+	"    `-- 0' coin type\n" +
+	"        `-- 0' account\n" +
+	"            `-- 0 receive\n" +
+	"                |-- 0 bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr\n" +
+	"                `-- 1 bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh\n"
+
+// TestTree checks the rendered derivation tree against the BIP-86 vectors,
+// and that the full tree has one purpose branch per type
+func TestTree(t *testing.T) {
+	wallet := testWallet(t)
+
+	root, err := wallet.DerivationTree([]AddressType{AddressTaproot}, 0, []uint32{0, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rendered := root.Render(); rendered != bip86Tree {
+		t.Fatalf("derivation tree mismatch:\n%s\nexpected:\n%s", rendered, bip86Tree)
+	}
+
+	root, err = wallet.DerivationTree(AddressTypes, 0, []uint32{0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(root.Children) != len(AddressTypes) {
+		t.Fatalf("derivation tree has %d purpose branches, expected %d", len(root.Children), len(AddressTypes))
+	}
+
+	// Every line is a node: the master, four levels per type and the leaf
+	if lines := strings.Count(root.Render(), "\n"); lines != 1+5*len(AddressTypes) {
+		t.Fatalf("derivation tree has %d lines, expected %d", lines, 1+5*len(AddressTypes))
+	}
+}
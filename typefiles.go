@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// TypeFileName returns the path of the address list of the type in dir,
+// e.g. p2wpkh.txt
+func TypeFileName(dir string, t AddressType) string {
+	return filepath.Join(dir, t.Name()+".txt")
+}
+
+// Address orders of the -sort flag
+const (
+	SortIndex   = "index"
+	SortAddress = "addr"
+)
+
+// ValidateSort checks a -sort value
+func ValidateSort(order string) error {
+	if order != SortIndex && order != SortAddress {
+		return fmt.Errorf("unknown sort order %q: must be %s or %s", order, SortIndex, SortAddress)
+	}
+
+	return nil
+}
+
+// indexedAddress is a derived address with the wallet and index it came from
+type indexedAddress struct {
+	Address string
+	Wallet  int
+	Index   uint32
+}
+
+// sortByAddress orders the addresses lexically, keeping the derivation order
+// of equal ones
+func sortByAddress(addresses []indexedAddress) {
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return addresses[i].Address < addresses[j].Address
+	})
+}
+
+// WriteTypeFiles writes one plain address list per selected type into dir,
+// one address per line across every wallet and index. No file is created
+// for unselected types. Sorted by address, each line also carries the wallet
+// number and index as address,wallet,index so the mapping is preserved.
+func WriteTypeFiles(dir string, types []AddressType, wallets []Generated, order string) error {
+	for _, t := range types {
+		if err := writeTypeFile(TypeFileName(dir, t), t, wallets, order); err != nil {
+			return fmt.Errorf("error writing %s addresses: %w", t, err)
+		}
+	}
+
+	return nil
+}
+
+func writeTypeFile(fileName string, t AddressType, wallets []Generated, order string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+
+	var addresses []indexedAddress
+	for i, wallet := range wallets {
+		for _, set := range wallet.AddressSets() {
+			addresses = append(addresses, indexedAddress{Address: set.Address(t).EncodeAddress(), Wallet: i + 1, Index: set.Index})
+		}
+	}
+
+	if order == SortAddress {
+		sortByAddress(addresses)
+	}
+
+	for _, address := range addresses {
+		line := address.Address
+		if order == SortAddress {
+			line = fmt.Sprintf("%s,%d,%d", address.Address, address.Wallet, address.Index)
+		}
+
+		if _, err := fmt.Fprintln(writer, line); err != nil {
+			return fmt.Errorf("error writing address: %w", err)
+		}
+	}
+
+	return writer.Flush()
+}
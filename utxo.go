@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// utxoJSON is an entry of the UTXO input file. Either the address or the
+// scriptPubKey identifies the output, index and change locate its key.
+type utxoJSON struct {
+	Txid         string `json:"txid"`
+	Vout         uint32 `json:"vout"`
+	Amount       int64  `json:"amount"`
+	Address      string `json:"address,omitempty"`
+	ScriptPubKey string `json:"script_pub_key,omitempty"`
+	Index        uint32 `json:"index"`
+	Change       uint32 `json:"change,omitempty"`
+	PrevTx       string `json:"prev_tx,omitempty"`
+}
+
+// UTXO is a validated unspent output of a derived address
+type UTXO struct {
+	OutPoint wire.OutPoint
+	Amount   btcutil.Amount
+	PkScript []byte
+	Change   uint32
+	Index    uint32
+
+	// PrevTx is the transaction creating the output, required to spend
+	// P2PKH outputs which do not commit to the amount in the signature
+	PrevTx *wire.MsgTx
+}
+
+// LoadUTXOs reads and validates the UTXO JSON file: an array of objects with
+// txid (64 hex characters), vout, amount in satoshis, address and/or
+// script_pub_key, index and optionally change and the hex prev_tx
+func LoadUTXOs(path string, params *chaincfg.Params) ([]UTXO, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading UTXO file: %w", err)
+	}
+
+	var entries []utxoJSON
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error decoding UTXO file: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("UTXO file has no entries")
+	}
+
+	utxos := make([]UTXO, 0, len(entries))
+	seen := make(map[wire.OutPoint]bool)
+
+	for i, entry := range entries {
+		utxo, err := entry.parse(params)
+		if err != nil {
+			return nil, fmt.Errorf("UTXO %d: %w", i+1, err)
+		}
+
+		if seen[utxo.OutPoint] {
+			return nil, fmt.Errorf("UTXO %d: duplicate outpoint %s", i+1, utxo.OutPoint)
+		}
+		seen[utxo.OutPoint] = true
+
+		utxos = append(utxos, utxo)
+	}
+
+	return utxos, nil
+}
+
+func (e utxoJSON) parse(params *chaincfg.Params) (UTXO, error) {
+	if len(e.Txid) != 2*chainhash.HashSize {
+		return UTXO{}, fmt.Errorf("txid must be %d hex characters, got %d", 2*chainhash.HashSize, len(e.Txid))
+	}
+
+	hash, err := chainhash.NewHashFromStr(e.Txid)
+	if err != nil {
+		return UTXO{}, fmt.Errorf("invalid txid: %w", err)
+	}
+
+	if e.Amount <= 0 || e.Amount > int64(btcutil.MaxSatoshi) {
+		return UTXO{}, fmt.Errorf("amount must be between 1 and %d satoshis, got %d", int64(btcutil.MaxSatoshi), e.Amount)
+	}
+
+	if e.Index >= hdkeychain.HardenedKeyStart {
+		return UTXO{}, fmt.Errorf("invalid index %d", e.Index)
+	}
+
+	var pkScript []byte
+	if len(e.ScriptPubKey) > 0 {
+		pkScript, err = hex.DecodeString(e.ScriptPubKey)
+		if err != nil {
+			return UTXO{}, fmt.Errorf("invalid script_pub_key: %w", err)
+		}
+	}
+
+	if len(e.Address) > 0 {
+		address, err := btcutil.DecodeAddress(e.Address, params)
+		if err != nil {
+			return UTXO{}, fmt.Errorf("invalid address: %w", err)
+		}
+
+		addressScript, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			return UTXO{}, fmt.Errorf("error creating output script: %w", err)
+		}
+
+		if pkScript != nil && !bytes.Equal(pkScript, addressScript) {
+			return UTXO{}, fmt.Errorf("script_pub_key does not match address %s", e.Address)
+		}
+		pkScript = addressScript
+	}
+
+	if pkScript == nil {
+		return UTXO{}, fmt.Errorf("address or script_pub_key is required")
+	}
+
+	var prevTx *wire.MsgTx
+	if len(e.PrevTx) > 0 {
+		raw, err := hex.DecodeString(e.PrevTx)
+		if err != nil {
+			return UTXO{}, fmt.Errorf("invalid prev_tx: %w", err)
+		}
+
+		prevTx = wire.NewMsgTx(wire.TxVersion)
+		if err := prevTx.Deserialize(bytes.NewReader(raw)); err != nil {
+			return UTXO{}, fmt.Errorf("invalid prev_tx: %w", err)
+		}
+
+		if prevTx.TxHash() != *hash {
+			return UTXO{}, fmt.Errorf("prev_tx hash %s does not match txid", prevTx.TxHash())
+		}
+
+		if int(e.Vout) >= len(prevTx.TxOut) {
+			return UTXO{}, fmt.Errorf("prev_tx has no output %d", e.Vout)
+		}
+
+		if out := prevTx.TxOut[e.Vout]; out.Value != e.Amount || !bytes.Equal(out.PkScript, pkScript) {
+			return UTXO{}, fmt.Errorf("prev_tx output %d does not match amount and script", e.Vout)
+		}
+	}
+
+	return UTXO{
+		OutPoint: wire.OutPoint{Hash: *hash, Index: e.Vout},
+		Amount:   btcutil.Amount(e.Amount),
+		PkScript: pkScript,
+		Change:   e.Change,
+		Index:    e.Index,
+		PrevTx:   prevTx,
+	}, nil
+}
+
+// UTXOType returns the address type whose derived address at the UTXO's
+// change and index pays to its output script, so the input can be signed
+func (w *Wallet) UTXOType(utxo UTXO) (AddressType, error) {
+	for _, t := range AddressTypes {
+		address, err := w.DeriveAddress(t, utxo.Change, utxo.Index)
+		if err != nil {
+			return 0, err
+		}
+
+		script, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			return 0, fmt.Errorf("error creating output script: %w", err)
+		}
+
+		if bytes.Equal(script, utxo.PkScript) {
+			return t, nil
+		}
+	}
+
+	return 0, fmt.Errorf("output %s is not paid to any derived address at change %d index %d", utxo.OutPoint, utxo.Change, utxo.Index)
+}
@@ -1,73 +1,268 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
 
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/text/unicode/norm"
 )
 
+// Wallet is an HD wallet. Its Derive methods are safe for concurrent use: the
+// master and cached chain keys have their public keys memoized up front and
+// are only read afterwards, every other node is derived fresh per call.
 type Wallet struct {
 	Entropy   []byte
 	Mnemonic  string
 	Seed      []byte
 	MasterKey *hdkeychain.ExtendedKey
+	Params    *chaincfg.Params
+
+	// Aezeed is set for wallets of an lnd cipher seed, whose Mnemonic holds
+	// the aezeed words instead of a BIP-39 mnemonic
+	Aezeed *Aezeed
+
+	// HybridPubKey derives the BIP-44 P2PKH addresses from the hybrid public
+	// key encoding, see SerializeHybrid. Only for recovering funds of very
+	// old software, no current wallet derives these addresses.
+	HybridPubKey bool
+
+	// chainKeys caches the m/purpose'/coin'/0'/change nodes so deriving many
+	// indices only performs the hardened derivations once per chain
+	mu        sync.Mutex
+	chainKeys map[[2]uint32]*hdkeychain.ExtendedKey
+
+	// watchAccount is the account key of a watch-only wallet, which stands
+	// in for the missing master key for its purpose watchPurpose
+	watchAccount *hdkeychain.ExtendedKey
+	watchPurpose uint32
 }
 
-func NewWallet(bitSize int) (*Wallet, error) {
+func NewWallet(bitSize int, passphrase string, params *chaincfg.Params) (*Wallet, error) {
 	// Generate a new mnemonic seed
 	entropy, err := bip39.NewEntropy(bitSize)
 	if err != nil {
 		return nil, fmt.Errorf(fmt.Sprintf("Error generating entropy: %v", err))
 	}
 
+	return NewWalletFromEntropy(entropy, passphrase, params)
+}
+
+// NewWalletWithRand generates a wallet reading its entropy from the provided
+// reader instead of crypto/rand, e.g. a hardware RNG or a deterministic DRBG
+func NewWalletWithRand(bitSize int, rand io.Reader, passphrase string, params *chaincfg.Params) (*Wallet, error) {
+	if bitSize%32 != 0 || bitSize < 128 || bitSize > 256 {
+		return nil, bip39.ErrEntropyLengthInvalid
+	}
+
+	entropy := make([]byte, bitSize/8)
+	if _, err := io.ReadFull(rand, entropy); err != nil {
+		return nil, fmt.Errorf("error reading entropy: %w", err)
+	}
+
+	return NewWalletFromEntropy(entropy, passphrase, params)
+}
+
+// ValidateWordCount checks that the mnemonic has one of the five BIP-39
+// lengths, explaining lengths that belong to other seed formats, before the
+// opaque go-bip39 checksum and word list errors
+func ValidateWordCount(mnemonic string) error {
+	count := len(strings.Fields(mnemonic))
+	if count >= 12 && count <= 24 && count%3 == 0 {
+		return nil
+	}
+
+	hint := ""
+	switch count {
+	case 13:
+		hint = ", a 13th word is often the BIP-39 passphrase written below the mnemonic, pass it with -passphrase"
+	case 25:
+		hint = ", 25 words is the length of a Monero seed which BIP-39 wallets cannot restore"
+	}
+
+	return fmt.Errorf("mnemonic has %d words, BIP-39 mnemonics have 12, 15, 18, 21 or 24%s", count, hint)
+}
+
+// normalizeMnemonic returns the form two spellings of the same mnemonic share:
+// NFKD normalized as for the seed derivation, lowercase and single spaced.
+// Pasted mnemonics often carry tabs, line breaks or capitalized words.
+func normalizeMnemonic(mnemonic string) string {
+	return strings.Join(strings.Fields(strings.ToLower(norm.NFKD.String(mnemonic))), " ")
+}
+
+// NewWalletFromMnemonic restores a wallet from an existing BIP-39 mnemonic,
+// see normalizeMnemonic for the spellings accepted
+func NewWalletFromMnemonic(mnemonic string, passphrase string, params *chaincfg.Params) (*Wallet, error) {
+	if err := ValidateWordCount(mnemonic); err != nil {
+		return nil, err
+	}
+
+	entropy, err := bip39.EntropyFromMnemonic(normalizeMnemonic(mnemonic))
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	return NewWalletFromEntropy(entropy, passphrase, params)
+}
+
+// NewWalletFromEntropy builds the mnemonic, seed and master key for the given
+// entropy. The wallet keeps its own copy, so the caller may zero entropy.
+func NewWalletFromEntropy(entropy []byte, passphrase string, params *chaincfg.Params) (*Wallet, error) {
+	entropy = bytes.Clone(entropy)
+
 	mnemonic, err := bip39.NewMnemonic(entropy)
 	if err != nil {
 		return nil, fmt.Errorf(fmt.Sprintf("Error generating mnemonic: %v", err))
 	}
 
-	// Generate a Bip32 HD wallet for the mnemonic and a user-supplied password
-	seed := bip39.NewSeed(mnemonic, "")
+	// Generate a Bip32 HD wallet for the mnemonic and a user-supplied password.
+	// BIP-39 requires both to be NFKD normalized, which only matters for
+	// non-ASCII wordlists and passphrases.
+	seed := newSeed(norm.NFKD.String(mnemonic), norm.NFKD.String(passphrase))
 
-	masterKey, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	masterKey, err := hdkeychain.NewMaster(seed, params)
 	if err != nil {
 		return nil, fmt.Errorf(fmt.Sprintf("Error generating master key: %v", err))
 	}
 
+	// ExtendedKey lazily memoizes its public key on first use, which every
+	// Derive does to compute the child's parent fingerprint. Memoize it now
+	// so concurrent derivations from the master key only read it.
+	if _, err := masterKey.ECPubKey(); err != nil {
+		return nil, fmt.Errorf("error getting master public key: %w", err)
+	}
+
 	return &Wallet{
 		Entropy:   entropy,
 		Mnemonic:  mnemonic,
 		Seed:      seed,
 		MasterKey: masterKey,
+		Params:    params,
 	}, nil
 }
 
-func (w *Wallet) ExtendMasterKey(bip uint32) (*hdkeychain.ExtendedKey, error) {
+// NewWatchOnlyWallet builds a wallet without private keys from the account
+// extended public key of the address type, e.g. m/84'/0'/0'. Only the
+// addresses of that type can be derived.
+func NewWatchOnlyWallet(account *hdkeychain.ExtendedKey, t AddressType, params *chaincfg.Params) (*Wallet, error) {
+	w := &Wallet{
+		Params:       params,
+		chainKeys:    make(map[[2]uint32]*hdkeychain.ExtendedKey),
+		watchAccount: account,
+		watchPurpose: t.Purpose(),
+	}
+
+	// Seed the chain cache of the standard chains
+	for change := uint32(0); change <= 1; change++ {
+		key, err := account.Derive(change)
+		if err != nil {
+			return nil, fmt.Errorf("error deriving change: %w", err)
+		}
+
+		w.chainKeys[[2]uint32{t.Purpose(), change}] = key
+	}
+
+	return w, nil
+}
+
+// Fingerprint returns the BIP-32 fingerprint of the master key: the first
+// four bytes of the Hash160 of its compressed public key
+func (w *Wallet) Fingerprint() ([]byte, error) {
+	pubKey, err := w.MasterKey.ECPubKey()
+	if err != nil {
+		return nil, fmt.Errorf("error getting master public key: %w", err)
+	}
+
+	return btcutil.Hash160(pubKey.SerializeCompressed())[:4], nil
+}
+
+// AccountKey derives the account node of the purpose: m/44'/0'/0'
+func (w *Wallet) AccountKey(bip uint32) (*hdkeychain.ExtendedKey, error) {
+	return w.AccountKeyAt(bip, 0)
+}
+
+// AccountKeyAt derives the node of the given account of the purpose: m/44'/0'/account'
+func (w *Wallet) AccountKeyAt(bip uint32, account uint32) (*hdkeychain.ExtendedKey, error) {
+	if w.MasterKey == nil {
+		if w.watchAccount != nil && bip == w.watchPurpose && account == 0 {
+			return w.watchAccount, nil
+		}
+
+		return nil, fmt.Errorf("watch-only wallet has no BIP-%d account", bip)
+	}
+
+	if account >= hdkeychain.HardenedKeyStart {
+		return nil, fmt.Errorf("invalid account %d", account)
+	}
+
 	purpose, err := w.MasterKey.Derive(hdkeychain.HardenedKeyStart + bip) // m/44'
 	if err != nil {
 		return nil, fmt.Errorf("error deriving purpose: %w", err)
 	}
 
-	coinType, err := purpose.Derive(hdkeychain.HardenedKeyStart + 0) // m/44'/0'
+	coinType, err := purpose.Derive(hdkeychain.HardenedKeyStart + w.Params.HDCoinType) // m/44'/0'
 	if err != nil {
 		return nil, fmt.Errorf("error deriving coin type: %w", err)
 	}
 
-	account, err := coinType.Derive(hdkeychain.HardenedKeyStart + 0) // m/44'/0'/0'
+	accountKey, err := coinType.Derive(hdkeychain.HardenedKeyStart + account) // m/44'/0'/0'
 	if err != nil {
 		return nil, fmt.Errorf("error deriving account: %w", err)
 	}
 
-	change, err := account.Derive(0) // m/44'/0'/0'/0
+	return accountKey, nil
+}
+
+// ChainKey returns the cached change node of the purpose: m/44'/0'/0'/change
+func (w *Wallet) ChainKey(bip uint32, change uint32) (*hdkeychain.ExtendedKey, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if key, ok := w.chainKeys[[2]uint32{bip, change}]; ok {
+		return key, nil
+	}
+
+	account, err := w.AccountKey(bip)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := account.Derive(change) // m/44'/0'/0'/0
 	if err != nil {
 		return nil, fmt.Errorf("error deriving change: %w", err)
 	}
 
-	addressIndex, err := change.Derive(0) // m/44'/0'/0'/0/0
+	// Memoize the public key while holding the lock, the cached node is
+	// otherwise only read when deriving children
+	if _, err := key.ECPubKey(); err != nil {
+		return nil, fmt.Errorf("error getting public key: %w", err)
+	}
+
+	if w.chainKeys == nil {
+		w.chainKeys = make(map[[2]uint32]*hdkeychain.ExtendedKey)
+	}
+	w.chainKeys[[2]uint32{bip, change}] = key
+
+	return key, nil
+}
+
+// ExtendMasterKey derives the key at index of the receive (change 0) or
+// change (change 1) chain of the purpose: m/44'/0'/0'/change/index
+func (w *Wallet) ExtendMasterKey(bip uint32, change uint32, index uint32) (*hdkeychain.ExtendedKey, error) {
+	chain, err := w.ChainKey(bip, change)
+	if err != nil {
+		return nil, err
+	}
+
+	addressIndex, err := chain.Derive(index) // m/44'/0'/0'/change/index
 	if err != nil {
 		return nil, fmt.Errorf("error deriving address index: %w", err)
 	}
@@ -75,15 +270,29 @@ func (w *Wallet) ExtendMasterKey(bip uint32) (*hdkeychain.ExtendedKey, error) {
 	return addressIndex, nil
 }
 
-// deriveP2PKHAddress derives the first P2PKH address using the BIP-44 path: m/44'/0'/0'/0/0
-func (w *Wallet) DeriveP2PKHAddress() (btcutil.Address, error) {
-	addressIndex, err := w.ExtendMasterKey(44)
+// DeriveP2PKHAddress derives the P2PKH address at index of the change chain using the BIP-44 path: m/44'/0'/0'/change/index
+func (w *Wallet) DeriveP2PKHAddress(change uint32, index uint32) (btcutil.Address, error) {
+	addressIndex, err := w.ExtendMasterKey(44, change, index)
 	if err != nil {
 		return nil, fmt.Errorf("error extending master key: %w", err)
 	}
 
+	if w.HybridPubKey {
+		pubKey, err := addressIndex.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("error getting public key: %w", err)
+		}
+
+		address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(SerializeHybrid(pubKey)), w.Params)
+		if err != nil {
+			return nil, fmt.Errorf("error generating address: %w", err)
+		}
+
+		return address, nil
+	}
+
 	// Convert to a Bitcoin address (P2PKH)
-	address, err := addressIndex.Address(&chaincfg.MainNetParams)
+	address, err := addressIndex.Address(w.Params)
 	if err != nil {
 		return nil, fmt.Errorf("error generating address: %w", err)
 	}
@@ -91,36 +300,100 @@ func (w *Wallet) DeriveP2PKHAddress() (btcutil.Address, error) {
 	return address, nil
 }
 
-// deriveP2WPKHInP2SHAddress derives the first P2WPKH-in-P2SH address using the BIP-49 path: m/49'/0'/0'/0/0
-func (w *Wallet) DeriveP2WPKHInP2SHAddress() (btcutil.Address, error) {
-	addressIndex, err := w.ExtendMasterKey(49)
+// SerializeHybrid encodes the public key in the hybrid format of OpenSSL:
+// the uncompressed encoding with the 0x04 prefix replaced by 0x06 or 0x07 for
+// an even or odd y coordinate. It is non-standard and hashes to addresses
+// distinct from both the compressed and uncompressed keys.
+func SerializeHybrid(pubKey *btcec.PublicKey) []byte {
+	serialized := pubKey.SerializeUncompressed()
+	serialized[0] = 0x06 | serialized[64]&1
+
+	return serialized
+}
+
+// DeriveLegacyBIP32Address derives the P2PKH address at index using the
+// pre-BIP-44 default account layout: m/0'/0/index. It exists to recover funds
+// from very old wallets and should not be used for new addresses.
+func (w *Wallet) DeriveLegacyBIP32Address(index uint32) (btcutil.Address, error) {
+	account, err := w.MasterKey.Derive(hdkeychain.HardenedKeyStart + 0) // m/0'
 	if err != nil {
-		return nil, fmt.Errorf("error extending master key: %w", err)
+		return nil, fmt.Errorf("error deriving account: %w", err)
 	}
 
-	// Extract the public key
-	pubKey, err := addressIndex.ECPubKey()
+	chain, err := account.Derive(0) // m/0'/0
 	if err != nil {
-		return nil, fmt.Errorf("error getting public key: %w", err)
+		return nil, fmt.Errorf("error deriving chain: %w", err)
 	}
 
-	// Generate the witness program (Hash160 of the public key)
-	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+	addressIndex, err := chain.Derive(index) // m/0'/0/index
+	if err != nil {
+		return nil, fmt.Errorf("error deriving address index: %w", err)
+	}
 
-	// Create the P2WPKH address
-	witnessPubKeyHash, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, &chaincfg.MainNetParams)
+	address, err := addressIndex.Address(w.Params)
 	if err != nil {
-		return nil, fmt.Errorf("error generating P2WPKH address: %w", err)
+		return nil, fmt.Errorf("error generating address: %w", err)
 	}
 
-	// Create the P2SH script
+	return address, nil
+}
+
+// nestedRedeemScriptLen is the length of the BIP-49 redeem script: OP_0
+// followed by the push of a 20 byte key hash
+const nestedRedeemScriptLen = 22
+
+// nestedRedeemScript returns the P2WPKH script a BIP-49 address wraps, checked
+// by CheckNestedRedeemScript before it is hashed into the P2SH address
+func nestedRedeemScript(witnessPubKeyHash btcutil.Address) ([]byte, error) {
 	script, err := txscript.PayToAddrScript(witnessPubKeyHash)
 	if err != nil {
 		return nil, fmt.Errorf("error creating P2SH script: %w", err)
 	}
 
+	if err := CheckNestedRedeemScript(script); err != nil {
+		return nil, err
+	}
+
+	return script, nil
+}
+
+// CheckNestedRedeemScript returns an error unless the script is exactly the
+// 22 byte version 0 witness program 0x0014 <20 byte key hash>. A script of any
+// other form would still hash into a valid looking P2SH address, but not one
+// that other BIP-49 wallets derive or that spends as P2WPKH.
+func CheckNestedRedeemScript(script []byte) error {
+	if len(script) != nestedRedeemScriptLen {
+		return fmt.Errorf("invalid P2SH-P2WPKH redeem script %x: %d bytes, expected %d", script, len(script), nestedRedeemScriptLen)
+	}
+
+	if script[0] != txscript.OP_0 || script[1] != txscript.OP_DATA_20 {
+		return fmt.Errorf("invalid P2SH-P2WPKH redeem script %x: must start with 0x0014", script)
+	}
+
+	return nil
+}
+
+// DeriveP2WPKHInP2SHAddress derives the P2WPKH-in-P2SH address at index of the change chain using the BIP-49 path: m/49'/0'/0'/change/index
+func (w *Wallet) DeriveP2WPKHInP2SHAddress(change uint32, index uint32) (btcutil.Address, error) {
+	addressIndex, err := w.ExtendMasterKey(49, change, index)
+	if err != nil {
+		return nil, fmt.Errorf("error extending master key: %w", err)
+	}
+
+	// Create the P2WPKH address
+	witnessPubKeyHash, err := w.witnessPubKeyHash(addressIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the P2SH script
+	script, err := nestedRedeemScript(witnessPubKeyHash)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the P2SH address
-	p2shAddress, err := btcutil.NewAddressScriptHash(script, &chaincfg.MainNetParams)
+	p2shAddress, err := btcutil.NewAddressScriptHash(script, w.Params)
 	if err != nil {
 		return nil, fmt.Errorf("error generating P2SH address: %w", err)
 	}
@@ -128,15 +401,22 @@ func (w *Wallet) DeriveP2WPKHInP2SHAddress() (btcutil.Address, error) {
 	return p2shAddress, nil
 }
 
-// deriveP2WPKHAddress derives the first native SegWit (P2WPKH) address using the BIP-84 path: m/84'/0'/0'/0/0
-func (w *Wallet) DeriveP2WPKHAddress() (btcutil.Address, error) {
-	addressIndex, err := w.ExtendMasterKey(84)
+// DeriveP2WPKHAddress derives the native SegWit (P2WPKH) address at index of the change chain using the BIP-84 path: m/84'/0'/0'/change/index
+func (w *Wallet) DeriveP2WPKHAddress(change uint32, index uint32) (btcutil.Address, error) {
+	addressIndex, err := w.ExtendMasterKey(84, change, index)
 	if err != nil {
 		return nil, fmt.Errorf("error extending master key: %w", err)
 	}
 
+	// Create the native SegWit (P2WPKH) address
+	return w.witnessPubKeyHash(addressIndex)
+}
+
+// witnessPubKeyHash returns the P2WPKH address of key, shared by the native
+// (BIP-84) and nested (BIP-49) SegWit derivations
+func (w *Wallet) witnessPubKeyHash(key *hdkeychain.ExtendedKey) (*btcutil.AddressWitnessPubKeyHash, error) {
 	// Extract the public key
-	pubKey, err := addressIndex.ECPubKey()
+	pubKey, err := key.ECPubKey()
 	if err != nil {
 		return nil, fmt.Errorf("error getting public key: %w", err)
 	}
@@ -144,8 +424,7 @@ func (w *Wallet) DeriveP2WPKHAddress() (btcutil.Address, error) {
 	// Generate the witness program (Hash160 of the public key)
 	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
 
-	// Create the native SegWit (P2WPKH) address
-	witnessPubKeyHash, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, &chaincfg.MainNetParams)
+	witnessPubKeyHash, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, w.Params)
 	if err != nil {
 		return nil, fmt.Errorf("error generating P2WPKH address: %w", err)
 	}
@@ -153,23 +432,32 @@ func (w *Wallet) DeriveP2WPKHAddress() (btcutil.Address, error) {
 	return witnessPubKeyHash, nil
 }
 
-// deriveTaprootAddress derives the first Taproot address using the BIP-86 path: m/86'/0'/0'/0/0
-func (w *Wallet) DeriveTaprootAddress() (btcutil.Address, error) {
-	addressIndex, err := w.ExtendMasterKey(86)
-	if err != nil {
-		return nil, fmt.Errorf("error extending master key: %w", err)
+// DeriveTaprootAddress derives the Taproot address at index of the change chain using the BIP-86 path: m/86'/0'/0'/change/index
+// If internalKey is not nil it is tweaked instead of the derived key, e.g. for an aggregated MuSig key
+func (w *Wallet) DeriveTaprootAddress(change uint32, index uint32, internalKey *btcec.PublicKey) (btcutil.Address, error) {
+	pubKey := internalKey
+	if pubKey == nil {
+		addressIndex, err := w.ExtendMasterKey(86, change, index)
+		if err != nil {
+			return nil, fmt.Errorf("error extending master key: %w", err)
+		}
+
+		// Extract the public key
+		pubKey, err = addressIndex.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("error getting public key: %w", err)
+		}
 	}
 
-	// Extract the public key
-	pubKey, err := addressIndex.ECPubKey()
+	tapKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+
+	outputKey, err := xOnlyOutputKey(tapKey)
 	if err != nil {
-		return nil, fmt.Errorf("error getting public key: %w", err)
+		return nil, err
 	}
 
-	tapKey := txscript.ComputeTaprootKeyNoScript(pubKey)
-
 	// Create the Taproot address
-	taprootAddress, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(tapKey), &chaincfg.MainNetParams)
+	taprootAddress, err := btcutil.NewAddressTaproot(outputKey, w.Params)
 	if err != nil {
 		return nil, fmt.Errorf("error generating Taproot address: %w", err)
 	}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// bip86Mnemonic is the mnemonic of the BIP-86 test vectors
+const bip86Mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// bip49Address and bip84Address are the first mainnet receive addresses of
+// the same mnemonic at the BIP-49 and BIP-84 paths
+const (
+	bip49Address = "37VucYSaXLCAsxYyAPfbSi9eh4iEcbShgf"
+	bip84Address = "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"
+)
+
+// bip84Zpub is the BIP-84 account zpub of the mnemonic, m/84'/0'/0'
+const bip84Zpub = "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"
+
+// bip86Vector is an address of the BIP-86 test vectors
+type bip86Vector struct {
+	change      uint32
+	index       uint32
+	internalKey string
+	outputKey   string
+	address     string
+}
+
+var bip86Vectors = []bip86Vector{
+	{
+		index:       0,
+		internalKey: "cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115",
+		outputKey:   "a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c",
+		address:     "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+	},
+	{
+		index:       1,
+		internalKey: "83dfe85a3151d2517290da461fe2815591ef69f2b18a2ce63f01697a8b313145",
+		outputKey:   "a82f29944d65b86ae6b5e5cc75e294ead6c59391a1edc5e016e3498c67fc7bbb",
+		address:     "bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh",
+	},
+	{
+		change:      1,
+		index:       0,
+		internalKey: "399f1b2f4393f29a18c937859c5dd8a77350103157eb880f02e8c08214277cef",
+		outputKey:   "882d74e5d0572d5a816cef0041a96b6c1de832f6f9676d9605c44d5e9a97d3dc",
+		address:     "bc1p3qkhfews2uk44qtvauqyr2ttdsw7svhkl9nkm9s9c3x4ax5h60wqwruhk7",
+	},
+}
+
+// testWallet restores the BIP-86 test vector mnemonic on mainnet
+func testWallet(t *testing.T) *Wallet {
+	t.Helper()
+
+	wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return wallet
+}
+
+// discardLogs silences the default logger for the test, for warnings the
+// test provokes on purpose
+func discardLogs(t *testing.T) {
+	t.Helper()
+
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+}
+
+// TestBIP86Vectors checks the Taproot derivation chain (ComputeTaprootKeyNoScript,
+// SerializePubKey and NewAddressTaproot) against the BIP-86 test vectors
+func TestBIP86Vectors(t *testing.T) {
+	wallet := testWallet(t)
+
+	for _, v := range bip86Vectors {
+		path := wallet.DerivationPath(86, v.change, v.index)
+		t.Run(path, func(t *testing.T) {
+			key, err := wallet.ExtendMasterKey(86, v.change, v.index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pubKey, err := key.ECPubKey()
+			if err != nil {
+				t.Fatalf("error getting public key: %v", err)
+			}
+
+			if internalKey := hex.EncodeToString(schnorr.SerializePubKey(pubKey)); internalKey != v.internalKey {
+				t.Errorf("internal key %s, expected %s", internalKey, v.internalKey)
+			}
+
+			outputKey := hex.EncodeToString(schnorr.SerializePubKey(txscript.ComputeTaprootKeyNoScript(pubKey)))
+			if outputKey != v.outputKey {
+				t.Errorf("output key %s, expected %s", outputKey, v.outputKey)
+			}
+
+			address, err := wallet.DeriveTaprootAddress(v.change, v.index, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if address.EncodeAddress() != v.address {
+				t.Errorf("address %s, expected %s", address.EncodeAddress(), v.address)
+			}
+		})
+	}
+}
+
+// TestWitnessPurposes guards the shared witness program helper: the BIP-49
+// and BIP-84 addresses must each commit to the key of their own purpose, so
+// a mixed up purpose constant makes them collide or mismatch
+func TestWitnessPurposes(t *testing.T) {
+	wallet := testWallet(t)
+
+	keyHash := func(bip uint32) ([]byte, error) {
+		key, err := wallet.ExtendMasterKey(bip, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			return nil, fmt.Errorf("error getting public key: %w", err)
+		}
+
+		return btcutil.Hash160(pubKey.SerializeCompressed()), nil
+	}
+
+	nestedHash, err := keyHash(49)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nativeHash, err := keyHash(84)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(nestedHash, nativeHash) {
+		t.Fatalf("BIP-49 and BIP-84 derived the same public key")
+	}
+
+	native, err := wallet.DeriveP2WPKHAddress(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(native.ScriptAddress(), nativeHash) {
+		t.Fatalf("BIP-84 %s: witness program %x, expected %x", wallet.DerivationPath(84, 0, 0), native.ScriptAddress(), nativeHash)
+	}
+
+	nested, err := wallet.DeriveP2WPKHInP2SHAddress(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redeemScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(nestedHash).Script()
+	if err != nil {
+		t.Fatalf("error building redeem script: %v", err)
+	}
+
+	if scriptHash := btcutil.Hash160(redeemScript); !bytes.Equal(nested.ScriptAddress(), scriptHash) {
+		t.Fatalf("BIP-49 %s: script hash %x, expected %x", wallet.DerivationPath(49, 0, 0), nested.ScriptAddress(), scriptHash)
+	}
+
+	for _, v := range []struct {
+		path    string
+		address btcutil.Address
+		want    string
+	}{
+		{wallet.DerivationPath(49, 0, 0), nested, bip49Address},
+		{wallet.DerivationPath(84, 0, 0), native, bip84Address},
+	} {
+		if v.address.EncodeAddress() != v.want {
+			t.Fatalf("%s: address %s, expected %s", v.path, v.address.EncodeAddress(), v.want)
+		}
+	}
+}
+
+// TestNestedChange checks that the BIP-49 change address differs from the
+// receive address and is the P2SH of the P2WPKH script of the change key
+func TestNestedChange(t *testing.T) {
+	wallet := testWallet(t)
+
+	receive, err := wallet.DeriveP2WPKHInP2SHAddress(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := wallet.DeriveP2WPKHInP2SHAddress(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if receive.EncodeAddress() == change.EncodeAddress() {
+		t.Fatalf("BIP-49 change address %s equals the receive address", change.EncodeAddress())
+	}
+
+	key, err := wallet.ExtendMasterKey(49, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		t.Fatalf("error getting public key: %v", err)
+	}
+
+	// OP_0 <20-byte key hash> wrapped in OP_HASH160 <script hash> OP_EQUAL
+	redeemScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).
+		AddData(btcutil.Hash160(pubKey.SerializeCompressed())).
+		Script()
+	if err != nil {
+		t.Fatalf("error building redeem script: %v", err)
+	}
+
+	expected, err := btcutil.NewAddressScriptHash(redeemScript, wallet.Params)
+	if err != nil {
+		t.Fatalf("error generating P2SH address: %v", err)
+	}
+
+	if change.EncodeAddress() != expected.EncodeAddress() {
+		t.Fatalf("BIP-49 %s: address %s, expected %s", wallet.DerivationPath(49, 1, 0), change.EncodeAddress(), expected.EncodeAddress())
+	}
+}
+
+// TestTaprootInternalKey derives the address of the receive index 0 with the
+// internal key of each BIP-86 vector supplied instead, which must tweak into
+// the output key and address of that vector rather than of index 0
+func TestTaprootInternalKey(t *testing.T) {
+	wallet := testWallet(t)
+
+	for _, v := range bip86Vectors {
+		serialized, err := hex.DecodeString(v.internalKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		internalKey, err := schnorr.ParsePubKey(serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		address, err := wallet.DeriveTaprootAddress(0, 0, internalKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if outputKey := hex.EncodeToString(address.ScriptAddress()); outputKey != v.outputKey {
+			t.Errorf("internal key %s tweaked to %s, expected %s", v.internalKey, outputKey, v.outputKey)
+		}
+
+		if encoded := address.EncodeAddress(); encoded != v.address {
+			t.Errorf("internal key %s derived %s, expected %s", v.internalKey, encoded, v.address)
+		}
+	}
+}
+
+// TestConcurrentDerive derives from one fresh wallet on several goroutines
+// and checks that they all agree. Run the tests with go test -race to
+// have the race detector audit the shared master and chain keys.
+func TestConcurrentDerive(t *testing.T) {
+	wallet := testWallet(t)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	results := make([]string, workers)
+	errs := make([]error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			fingerprint, err := wallet.Fingerprint()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			set, err := wallet.DeriveAll(uint32(i%2), 0)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			results[i] = hex.EncodeToString(fingerprint)
+			for _, typ := range AddressTypes {
+				results[i] += " " + set.Address(typ).EncodeAddress()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 2; i < workers; i++ {
+		if results[i] != results[i%2] {
+			t.Fatalf("concurrent derivation %d returned %q, expected %q", i, results[i], results[i%2])
+		}
+	}
+
+	if !strings.Contains(results[0], bip84Address) {
+		t.Fatalf("concurrent derivation returned %q, expected %s", results[0], bip84Address)
+	}
+}
+
+// TestWordCount checks that 13 and 25 word phrases are rejected up front
+// with the count found and the valid lengths
+func TestWordCount(t *testing.T) {
+	words := strings.Fields(bip86Mnemonic)
+
+	for _, count := range []int{13, 25} {
+		phrase := make([]string, count)
+		for i := range phrase {
+			phrase[i] = words[i%len(words)]
+		}
+
+		_, err := NewWalletFromMnemonic(strings.Join(phrase, " "), "", &chaincfg.MainNetParams)
+		if err == nil {
+			t.Fatalf("%d word mnemonic was accepted", count)
+		}
+
+		if !strings.Contains(err.Error(), fmt.Sprintf("has %d words", count)) || !strings.Contains(err.Error(), "12, 15, 18, 21 or 24") {
+			t.Fatalf("%d word mnemonic error %q does not name the count and valid lengths", count, err)
+		}
+	}
+}
+
+// TestMnemonicSpacing checks pasted mnemonics restore regardless of their
+// whitespace and capitalization
+func TestMnemonicSpacing(t *testing.T) {
+	pasted := []string{
+		strings.ReplaceAll(bip86Mnemonic, " ", "\t"),
+		"  Abandon ABANDON abandon\tabandon  abandon abandon\nabandon abandon abandon abandon abandon About\r\n",
+	}
+
+	for _, mnemonic := range pasted {
+		wallet, err := NewWalletFromMnemonic(mnemonic, "", &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("pasted mnemonic %q: %v", mnemonic, err)
+		}
+
+		address, err := wallet.DeriveAddress(AddressP2WPKH, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if wallet.Mnemonic != bip86Mnemonic || address.EncodeAddress() != bip84Address {
+			t.Fatalf("pasted mnemonic %q restored a different wallet", mnemonic)
+		}
+	}
+}
+
+// TestHybridPubKey checks -hybrid-pubkey derives the P2PKH address of the
+// 0x06/0x07 encoding, distinct from the compressed and uncompressed ones
+func TestHybridPubKey(t *testing.T) {
+	wallet := testWallet(t)
+	wallet.HybridPubKey = true
+
+	key, err := wallet.ExtendMasterKey(44, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hybrid := append([]byte{0x06 + byte(pubKey.Y().Bit(0))}, pubKey.SerializeUncompressed()[1:]...)
+
+	addresses := make(map[string]string)
+	for name, serialized := range map[string][]byte{"compressed": pubKey.SerializeCompressed(), "uncompressed": pubKey.SerializeUncompressed(), "hybrid": hybrid} {
+		address, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(serialized), &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if other, ok := addresses[address.EncodeAddress()]; ok {
+			t.Fatalf("%s and %s keys share the address %s", other, name, address.EncodeAddress())
+		}
+		addresses[address.EncodeAddress()] = name
+	}
+
+	derived, err := wallet.DeriveP2PKHAddress(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addresses[derived.EncodeAddress()] != "hybrid" {
+		t.Fatalf("hybrid P2PKH address %s, expected that of the %x key", derived.EncodeAddress(), hybrid)
+	}
+}
+
+// TestNestedRedeemScript checks that the BIP-49 redeem scripts are 22 byte
+// witness programs and that scripts of another length or form are rejected
+func TestNestedRedeemScript(t *testing.T) {
+	wallet := testWallet(t)
+
+	for index := uint32(0); index < 5; index++ {
+		key, err := wallet.ExtendMasterKey(49, 0, index)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		witnessPubKeyHash, err := wallet.witnessPubKeyHash(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		script, err := nestedRedeemScript(witnessPubKeyHash)
+		if err != nil {
+			t.Fatalf("BIP-49 %s: %v", wallet.DerivationPath(49, 0, index), err)
+		}
+
+		if len(script) != 22 {
+			t.Fatalf("BIP-49 %s: redeem script of %d bytes", wallet.DerivationPath(49, 0, index), len(script))
+		}
+	}
+
+	hash := bytes.Repeat([]byte{0xab}, 20)
+	for _, script := range [][]byte{
+		append([]byte{txscript.OP_0, txscript.OP_DATA_20}, hash[:19]...),
+		append([]byte{txscript.OP_0, txscript.OP_DATA_20}, append(hash, 0)...),
+		append([]byte{txscript.OP_1, txscript.OP_DATA_20}, hash...),
+		append([]byte{txscript.OP_0, txscript.OP_DATA_32}, bytes.Repeat([]byte{0xab}, 32)...),
+	} {
+		if err := CheckNestedRedeemScript(script); err == nil {
+			t.Fatalf("invalid redeem script %x accepted", script)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// WriteWatchOnlyAddresses derives the addresses at indices of the change
+// chain from an account extended public key and writes one per line. The
+// address type is taken from a SLIP-132 prefix or typeName, which is required
+// for plain xpub/tpub keys. The lines follow indices, or the address order
+// when order is SortAddress. A key not at the account level is warned
+// about, see CheckAccountKey.
+func WriteWatchOnlyAddresses(w io.Writer, key string, typeName string, params *chaincfg.Params, change uint32, indices []uint32, order string) error {
+	account, t, ok, err := ParseExtendedPublicKey(key, params)
+	if err != nil {
+		return err
+	}
+
+	// Some wallets, e.g. Electrum, derive straight from the root, so a key at
+	// another level is only suspicious
+	if err := CheckAccountKey(account); err != nil {
+		slog.Warn("the addresses may not match the wallet", "error", err)
+	}
+
+	if len(typeName) > 0 {
+		typed, err := ParseAddressType(typeName)
+		if err != nil {
+			return err
+		}
+
+		if ok && typed != t {
+			return fmt.Errorf("address type %s does not match the %s key prefix", typed.Name(), t.Name())
+		}
+		t, ok = typed, true
+	}
+
+	if !ok {
+		return fmt.Errorf("the address type is required for a plain xpub")
+	}
+
+	wallet, err := NewWatchOnlyWallet(account, t, params)
+	if err != nil {
+		return err
+	}
+
+	addresses, err := wallet.DeriveIndices(t, change, indices)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]indexedAddress, 0, len(addresses))
+	for i, address := range addresses {
+		lines = append(lines, indexedAddress{Address: address.EncodeAddress(), Index: indices[i]})
+	}
+
+	if order == SortAddress {
+		sortByAddress(lines)
+	}
+
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s %s #%d: %s\n", t, ChainName(change), line.Index, line.Address)
+	}
+
+	return nil
+}
+
+// indexRange returns the indices to derive: the explicit list if given,
+// otherwise 0 through count-1
+func indexRange(indexList []uint32, count int) []uint32 {
+	if len(indexList) > 0 {
+		return indexList
+	}
+
+	indices := make([]uint32, 0, count)
+	for index := uint32(0); index < uint32(count); index++ {
+		indices = append(indices, index)
+	}
+
+	return indices
+}
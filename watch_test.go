@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestWatchOnly checks that the BIP-84 zpub of the mnemonic decodes and
+// derives its first address
+func TestWatchOnly(t *testing.T) {
+	account, typ, ok, err := ParseExtendedPublicKey(bip84Zpub, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok || typ != AddressP2WPKH {
+		t.Fatalf("zpub decoded as %s, expected %s", typ, AddressP2WPKH)
+	}
+
+	wallet, err := NewWatchOnlyWallet(account, typ, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := wallet.DeriveAddress(typ, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if address.EncodeAddress() != bip84Address {
+		t.Fatalf("zpub address %s, expected %s", address.EncodeAddress(), bip84Address)
+	}
+}
+
+// TestWatchOnlyFidelity derives indices 0 to 9 of both chains of every type
+// from the seed and, as an auditor would, from the serialized account xpub
+// through WriteWatchOnlyAddresses, and checks both list the same addresses
+func TestWatchOnlyFidelity(t *testing.T) {
+	wallet := testWallet(t)
+
+	indices := indexRange(nil, 10)
+
+	for _, typ := range AddressTypes {
+		account, err := wallet.AccountKey(typ.Purpose())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		xpub, err := account.Neuter()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for change := uint32(0); change <= 1; change++ {
+			var expected strings.Builder
+			for _, index := range indices {
+				address, err := wallet.DeriveAddress(typ, change, index)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				fmt.Fprintf(&expected, "%s %s #%d: %s\n", typ, ChainName(change), index, address.EncodeAddress())
+			}
+
+			var watched bytes.Buffer
+			if err := WriteWatchOnlyAddresses(&watched, xpub.String(), typ.Name(), wallet.Params, change, indices, SortIndex); err != nil {
+				t.Fatalf("%s xpub: %v", typ.Name(), err)
+			}
+
+			if watched.String() != expected.String() {
+				t.Fatalf("%s %s addresses from the xpub differ from the seed:\n%s", typ.Name(), ChainName(change), watched.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+// MnemonicFromIndices maps a space or comma separated list of word indices,
+// 0 to 2047, to the words of the active BIP-39 word list. Some devices show
+// the 11-bit indices instead of the words. The word count and checksum are
+// validated like a typed mnemonic.
+func MnemonicFromIndices(list string) (string, error) {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+
+	wordList := bip39.GetWordList()
+
+	words := make([]string, 0, len(fields))
+	for i, field := range fields {
+		index, err := strconv.Atoi(field)
+		if err != nil {
+			return "", fmt.Errorf("word %d: %q is not a number", i+1, field)
+		}
+
+		if index < 0 || index >= len(wordList) {
+			hint := ""
+			if index == len(wordList) {
+				hint = ", indices count from 0, subtract 1 from each if the device counts from 1"
+			}
+
+			return "", fmt.Errorf("word %d: index %d is out of range 0 to %d%s", i+1, index, len(wordList)-1, hint)
+		}
+
+		words = append(words, wordList[index])
+	}
+
+	mnemonic := strings.Join(words, " ")
+	if err := ValidateWordCount(mnemonic); err != nil {
+		return "", err
+	}
+
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return "", fmt.Errorf("word indices fail the BIP-39 checksum, check the last index and the order")
+	}
+
+	return mnemonic, nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+// TestWordIndices checks that the word indices of the BIP-86 test vector
+// mnemonic restore it in both separator styles, and that out of range
+// indices, wrong counts and a bad checksum are rejected
+func TestWordIndices(t *testing.T) {
+	for _, list := range []string{"0 0 0 0 0 0 0 0 0 0 0 3", "0,0,0,0,0,0,0,0,0,0,0,3", " 0, 0 0,0 0 0 0 0 0 0 0 3 "} {
+		mnemonic, err := MnemonicFromIndices(list)
+		if err != nil {
+			t.Fatalf("word indices %q rejected: %v", list, err)
+		}
+
+		if mnemonic != bip86Mnemonic {
+			t.Fatalf("word indices %q mapped to %q", list, mnemonic)
+		}
+	}
+
+	for _, list := range []string{"0 0 0 0 0 0 0 0 0 0 0 2048", "0 0 0 0 0 0 0 0 0 0 0 -1", "0 0 0 0 0 0 0 0 0 0 3", "0 0 0 0 0 0 0 0 0 0 0 0"} {
+		if _, err := MnemonicFromIndices(list); err == nil {
+			t.Fatalf("word indices %q were accepted", list)
+		}
+	}
+}
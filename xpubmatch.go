@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// xpubNetwork parses an account extended public key in the standard or
+// SLIP-132 encoding of any known network and returns it in the standard one
+func xpubNetwork(xpub string) (*hdkeychain.ExtendedKey, *chaincfg.Params, error) {
+	for _, name := range networkNames {
+		key, _, _, err := ParseExtendedPublicKey(xpub, networks[name])
+		if err == nil {
+			return key, networks[name], nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("extended public key %q does not belong to any known network", xpub)
+}
+
+// accountXPubMatches reports whether the account xpub of the wallet at
+// m/bip'/coin'/account' equals key
+func (w *Wallet) accountXPubMatches(key *hdkeychain.ExtendedKey, bip uint32, account uint32) (bool, error) {
+	accountKey, err := w.AccountKeyAt(bip, account)
+	if err != nil {
+		return false, err
+	}
+
+	xpub, err := accountKey.Neuter()
+	if err != nil {
+		return false, fmt.Errorf("error deriving account xpub: %w", err)
+	}
+
+	return xpub.String() == key.String(), nil
+}
+
+// MnemonicMatchesXPub reports whether the account xpub derived from the
+// mnemonic and passphrase at m/bip'/coin'/account' equals xpub. The network
+// and coin type follow from the xpub, which may be SLIP-132 encoded.
+func MnemonicMatchesXPub(mnemonic string, passphrase string, xpub string, bip uint32, account uint32) (bool, error) {
+	key, params, err := xpubNetwork(xpub)
+	if err != nil {
+		return false, err
+	}
+
+	wallet, err := NewWalletFromMnemonic(mnemonic, passphrase, params)
+	if err != nil {
+		return false, err
+	}
+
+	return wallet.accountXPubMatches(key, bip, account)
+}
+
+// XPubMatch describes the combination under which a mnemonic derived an xpub
+type XPubMatch struct {
+	Network string
+	Purpose uint32
+	Account uint32
+	Path    string
+
+	// Passphrase describes the passphrase variant that matched, e.g. "as
+	// given" or "empty", without revealing the passphrase
+	Passphrase string
+}
+
+// passphraseVariant is a common mistake in entering a BIP-39 passphrase
+type passphraseVariant struct {
+	name  string
+	apply func(passphrase string) string
+}
+
+var passphraseVariants = []passphraseVariant{
+	{"as given", func(p string) string { return p }},
+	{"empty", func(p string) string { return "" }},
+	{"without surrounding whitespace", strings.TrimSpace},
+	{"lowercase", strings.ToLower},
+	{"with the first letter case swapped", swapFirstLetterCase},
+}
+
+// swapFirstLetterCase undoes the capitalization phone keyboards add
+func swapFirstLetterCase(p string) string {
+	if len(p) == 0 {
+		return p
+	}
+
+	first := p[:1]
+	if upper := strings.ToUpper(first); upper != first {
+		return upper + p[1:]
+	}
+
+	return strings.ToLower(first) + p[1:]
+}
+
+// FindXPubMatch checks the mnemonic against xpub like MnemonicMatchesXPub,
+// and on a mismatch retries the combinations users commonly get wrong: the
+// passphrase variants, every supported purpose and the first accounts. It
+// returns nil if none matches.
+func FindXPubMatch(mnemonic string, passphrase string, xpub string, bip uint32, account uint32, maxAccount uint32) (*XPubMatch, error) {
+	key, params, err := xpubNetwork(xpub)
+	if err != nil {
+		return nil, err
+	}
+
+	purposes := []uint32{bip}
+	for _, t := range AddressTypes {
+		if t.Purpose() != bip {
+			purposes = append(purposes, t.Purpose())
+		}
+	}
+
+	accounts := []uint32{account}
+	for a := uint32(0); a <= maxAccount; a++ {
+		if a != account {
+			accounts = append(accounts, a)
+		}
+	}
+
+	tried := make(map[string]bool)
+	for _, variant := range passphraseVariants {
+		candidate := variant.apply(passphrase)
+		if tried[candidate] {
+			continue
+		}
+		tried[candidate] = true
+
+		wallet, err := NewWalletFromMnemonic(mnemonic, candidate, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, purpose := range purposes {
+			for _, a := range accounts {
+				ok, err := wallet.accountXPubMatches(key, purpose, a)
+				if err != nil {
+					return nil, err
+				}
+
+				if ok {
+					return &XPubMatch{
+						Network:    params.Name,
+						Purpose:    purpose,
+						Account:    a,
+						Path:       fmt.Sprintf("m/%d'/%d'/%d'", purpose, params.HDCoinType, a),
+						Passphrase: variant.name,
+					}, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// TestXPubMatch checks the mnemonic against its BIP-84 testnet tpub at the
+// right and a wrong purpose, and that a stray passphrase is recognized as the
+// mistake. It uses testnet, which every build includes.
+func TestXPubMatch(t *testing.T) {
+	wallet, err := NewWalletFromMnemonic(bip86Mnemonic, "", &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := wallet.AccountKey(84)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpub, err := account.Neuter()
+	if err != nil {
+		t.Fatalf("error deriving account xpub: %v", err)
+	}
+
+	for _, v := range []struct {
+		bip   uint32
+		match bool
+	}{
+		{84, true},
+		{49, false},
+	} {
+		ok, err := MnemonicMatchesXPub(bip86Mnemonic, "", tpub.String(), v.bip, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok != v.match {
+			t.Fatalf("BIP-%d xpub match %t, expected %t", v.bip, ok, v.match)
+		}
+	}
+
+	match, err := FindXPubMatch(bip86Mnemonic, "stray", tpub.String(), 84, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if match == nil || match.Passphrase != "empty" || match.Path != "m/84'/1'/0'" {
+		t.Fatalf("xpub match with a stray passphrase %+v, expected the empty passphrase at m/84'/1'/0'", match)
+	}
+}